@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var allowDescription string
+
+var allowCmd = &cobra.Command{
+	Use:   "allow <port|range|pattern>",
+	Short: "Allow a port or range of ports",
+	Long: `Allow a port, range of ports (e.g. 4000-4010), or pattern (e.g. 40xx). Once any
+ports are allowed, only allowed ports that are not blocked are ever assigned,
+and auto-assignment only scans the allowed ranges. A range whose every port is
+blocked is refused. Existing assignments outside the allowed ranges are kept
+with a warning.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		spec := args[0]
+		disallowed, err := reg.AllowPorts(spec, allowDescription)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range disallowed {
+			fmt.Fprintf(os.Stderr, "Warning: port %d is assigned to '%s' but is outside the allowed ranges\n", a.Port, a.Description)
+		}
+
+		printInfo("Allowed %s\n", spec)
+		return nil
+	},
+}
+
+func init() {
+	allowCmd.Flags().StringVarP(&allowDescription, "description", "d", "", "Description for the allowed ports")
+	rootCmd.AddCommand(allowCmd)
+}
@@ -4,6 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/portreg/registry"
 	"github.com/spf13/cobra"
@@ -11,21 +15,64 @@ import (
 
 var (
 	assignPort        int
+	assignStart       int
+	assignEnd         int
+	assignCount       int
+	assignVerify      bool
+	assignDryRun      bool
 	assignPath        string
 	assignDescription string
+	assignTags        []string
+	assignNotes       string
+	assignFromFile    string
+	assignFailFast    bool
+	assignFormat      string
+	assignNoAutoDesc  bool
+	assignForce       bool
+	assignName        string
+	assignProtocol    string
+	assignTTL         time.Duration
+	assignIn          string
+	assignStrict      bool
+	assignLabel       string
 )
 
 var assignCmd = &cobra.Command{
 	Use:   "assign",
 	Short: "Assign a port to a project",
 	Long: `Assign a port to a project. If no port is specified, automatically assigns
-the next available port starting from 3100.`,
+the next available port starting from 3100 (or the registry's configured start
+port) and ending at the registry's configured end port.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		specificPort := cmd.Flags().Changed("port")
+		if err := checkAssignCount(specificPort, assignCount); err != nil {
+			return err
+		}
 
-		reg, err := registry.New(registryPath)
-		if err != nil {
-			return fmt.Errorf("failed to load registry: %w", err)
+		// Edit before taking the lock, which is held until the command ends
+		if assignDescription == editDescriptionArg {
+			var err error
+			assignDescription, assignNotes, err = editDescription(assignNotes)
+			if err != nil {
+				return err
+			}
+		}
+
+		var reg *registry.Registry
+		var err error
+		if assignDryRun {
+			reg, err = loadRegistry()
+			if err != nil {
+				return err
+			}
+			reg.SetDryRun(true)
+		} else {
+			reg, err = openLockedRegistry()
+			if err != nil {
+				return err
+			}
+			defer reg.Unlock()
 		}
 
 		// Use current directory if no path specified
@@ -33,32 +80,323 @@ the next available port starting from 3100.`,
 			assignPath, _ = os.Getwd()
 		}
 
-		if assignPort > 0 {
+		reg.SetVerify(assignVerify)
+		reg.SetAllowAutoRange(assignForce)
+
+		if assignStart > 0 {
+			reg.SetStartPort(assignStart)
+		}
+		if assignEnd > 0 {
+			reg.SetEndPort(assignEnd)
+		}
+
+		a := registry.Assignment{
+			Name:        assignName,
+			Description: assignDescription,
+			Path:        assignPath,
+			Tags:        assignTags,
+			Notes:       assignNotes,
+			Label:       assignLabel,
+		}
+		if cmd.Flags().Changed("protocol") {
+			a.Protocol = assignProtocol
+		}
+		if assignTTL < 0 {
+			return fmt.Errorf("--ttl must not be negative")
+		}
+		if assignTTL > 0 {
+			a.ExpiresAt = time.Now().Add(assignTTL).UTC().Truncate(time.Second)
+		}
+		if assignFromFile == "" {
+			a.Description, err = expandDescription(a.Description, a.Path)
+			if err != nil {
+				return err
+			}
+			a.Description = defaultDescription(a.Description, a.Path)
+			if err := checkDescription(reg, a.Description, a.Path); err != nil {
+				return err
+			}
+		}
+
+		var assigned []int
+		if assignFromFile != "" {
+			// Auto-assign a port to each path listed in a file
+			assigned, err = assignFromPathsFile(reg, assignFromFile, a)
+			if !assignDryRun {
+				runChangeHook(reg, hookAssign, assigned...)
+			}
+			if err != nil {
+				return err
+			}
+		} else if specificPort {
 			// Assign specific port
-			err = reg.AssignPort(assignPort, assignDescription, assignPath)
+			a.Port = assignPort
+			err = reg.Assign(a)
 			if err != nil {
-				if errors.Is(err, registry.ErrPortAlreadyAssigned) {
-					return fmt.Errorf("%w. Use 'portreg list' to see all assignments", err)
+				var conflict *registry.PortConflictError
+				if errors.As(err, &conflict) {
+					path := conflict.Assignment.Path
+					if path == "" {
+						path = "-"
+					}
+					return fmt.Errorf("%w (path: %s). Use 'portreg list' to see all assignments", err, path)
+				}
+				if errors.Is(err, registry.ErrInvalidPort) {
+					return fmt.Errorf("cannot assign port: %w", err)
+				}
+				if errors.Is(err, registry.ErrPortInAutoRange) {
+					return fmt.Errorf("%w. Use --force to assign it anyway", err)
 				}
 				return err
 			}
-			fmt.Println(assignPort)
+			assigned = []int{a.Port}
+			if err := printAssigned(a); err != nil {
+				return err
+			}
+		} else if assignIn != "" {
+			// Auto-assign the first available port in a range
+			start, end, err := registry.ParsePortRange(assignIn)
+			if err != nil {
+				return err
+			}
+			port, err := reg.AssignNextIn(start, end, a)
+			if err != nil {
+				return err
+			}
+			assigned = []int{port}
+			warnNoteworthy(port)
+			a.Port = port
+			if err := printAssigned(a); err != nil {
+				return err
+			}
+		} else if assignCount > 1 {
+			// Auto-assign a block of consecutive ports
+			ports, err := reg.AssignNextBlock(assignCount, a)
+			if err != nil {
+				return err
+			}
+			assigned = ports
+			for _, port := range ports {
+				warnNoteworthy(port)
+			}
+			if assignFormat == "json" {
+				assigned := make([]registry.Assignment, 0, len(ports))
+				for _, port := range ports {
+					a.Port = port
+					assigned = append(assigned, a)
+				}
+				if err := printCompactJSON(assigned); err != nil {
+					return err
+				}
+			} else {
+				for _, port := range ports {
+					fmt.Println(port)
+				}
+			}
 		} else {
 			// Auto-assign next available port
-			port, err := reg.AssignNextAvailable(assignDescription, assignPath)
+			port, err := reg.AssignNext(a)
 			if err != nil {
 				return err
 			}
-			fmt.Println(port)
+			assigned = []int{port}
+			warnNoteworthy(port)
+			a.Port = port
+			if err := printAssigned(a); err != nil {
+				return err
+			}
+		}
+
+		if assignDryRun {
+			fmt.Fprintln(os.Stderr, "Dry run: no changes were saved")
+		} else if assignFromFile == "" {
+			runChangeHook(reg, hookAssign, assigned...)
 		}
 
 		return nil
 	},
 }
 
+// printAssigned prints an assigned port, or the whole assignment with --format json
+func printAssigned(a registry.Assignment) error {
+	if assignFormat == "json" {
+		return printCompactJSON(a)
+	}
+	fmt.Println(a.Port)
+	return nil
+}
+
+// checkDescription warns on stderr if description is already used by the
+// assignments of a project other than path, or fails with --strict
+func checkDescription(reg *registry.Registry, description, path string) error {
+	ports := reg.DescriptionInUse(description, path)
+	if len(ports) == 0 {
+		return nil
+	}
+
+	used := make([]string, len(ports))
+	for i, port := range ports {
+		used[i] = strconv.Itoa(port)
+	}
+	msg := fmt.Sprintf("description '%s' is already used by port(s) %s", description, strings.Join(used, ", "))
+	if assignStrict {
+		return errors.New(msg)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+	return nil
+}
+
+// warnNoteworthy prints an advisory to stderr if an auto-assigned port is
+// commonly used by development tools, unless --quiet is set
+func warnNoteworthy(port int) {
+	if quiet {
+		return
+	}
+	if usedBy, ok := registry.NoteworthyPort(port); ok {
+		fmt.Fprintf(os.Stderr, "Warning: port %d is commonly used by %s\n", port, usedBy)
+	}
+}
+
+// defaultDescription returns description, or the base name of path if
+// description is empty and --no-auto-description is not set
+func defaultDescription(description, path string) string {
+	if description != "" || path == "" || assignNoAutoDesc {
+		return description
+	}
+	return filepath.Base(path)
+}
+
+// expandDescription replaces the placeholders {basename} (the base name of
+// path), {host} (the hostname), and {date} (today as YYYY-MM-DD) in
+// description. Other text, including unknown placeholders, is kept as-is.
+func expandDescription(description, path string) (string, error) {
+	if !strings.Contains(description, "{") {
+		return description, nil
+	}
+
+	var host string
+	if strings.Contains(description, "{host}") {
+		var err error
+		host, err = os.Hostname()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand {host}: %w", err)
+		}
+	}
+
+	var basename string
+	if path != "" {
+		basename = filepath.Base(path)
+	}
+
+	return strings.NewReplacer(
+		"{basename}", basename,
+		"{host}", host,
+		"{date}", time.Now().Format(time.DateOnly),
+	).Replace(description), nil
+}
+
+// assignFromPathsFile auto-assigns the next available port to each non-empty
+// line of a file, using the line as the path. Unless the template has a
+// description, the base name of the path is used. Errors are reported per line
+// and assignment continues unless --fail-fast is set. The assigned ports are
+// returned even when some lines fail.
+func assignFromPathsFile(reg *registry.Registry, filename string, template registry.Assignment) ([]int, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read paths file: %w", err)
+	}
+
+	var assigned []int
+	var failed, total int
+	for i, line := range strings.Split(string(data), "\n") {
+		path := strings.TrimSpace(line)
+		if path == "" {
+			continue
+		}
+		total++
+
+		a := template
+		a.Path = path
+		a.Description, err = expandDescription(a.Description, path)
+		if err != nil {
+			return assigned, err
+		}
+		a.Description = defaultDescription(a.Description, path)
+
+		err = checkDescription(reg, a.Description, path)
+		var port int
+		if err == nil {
+			port, err = reg.AssignNext(a)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "line %d: %s: %v\n", i+1, path, err)
+			if assignFailFast {
+				return assigned, err
+			}
+			continue
+		}
+		assigned = append(assigned, port)
+		warnNoteworthy(port)
+
+		if assignFormat == "json" {
+			a.Port = port
+			if err := printCompactJSON(a); err != nil {
+				return assigned, err
+			}
+		} else {
+			fmt.Printf("%d\t%s\n", port, path)
+		}
+	}
+
+	if failed > 0 {
+		return assigned, fmt.Errorf("failed to assign %d of %d path(s)", failed, total)
+	}
+	return assigned, nil
+}
+
+// checkAssignCount validates --count, which must be positive and cannot be
+// combined with --port
+func checkAssignCount(specificPort bool, count int) error {
+	if count < 1 {
+		return fmt.Errorf("invalid port count: %d (must be at least 1)", count)
+	}
+	if specificPort && count > 1 {
+		return fmt.Errorf("--port and --count cannot be used together")
+	}
+	return nil
+}
+
 func init() {
 	assignCmd.Flags().IntVarP(&assignPort, "port", "p", 0, "Specific port to assign")
+	assignCmd.Flags().IntVar(&assignStart, "start", 0, "Port to start auto-assignment from (overrides registry start port)")
+	assignCmd.Flags().IntVar(&assignEnd, "end", 0, "Last port auto-assignment may use (overrides registry end port)")
+	assignCmd.Flags().StringVar(&assignIn, "in", "", "Assign the first available port in this range (e.g. 8000-8099)")
+	assignCmd.Flags().IntVar(&assignCount, "count", 1, "Number of consecutive ports to assign")
+	assignCmd.Flags().BoolVar(&assignVerify, "verify", false, "Refuse ports that cannot currently be bound on this machine")
+	assignCmd.Flags().BoolVar(&assignForce, "force", false, "Assign a specific port even if it is in a protected auto-assignment range")
+	assignCmd.Flags().BoolVar(&assignDryRun, "dry-run", false, "Check and print the port(s) that would be assigned without saving")
 	assignCmd.Flags().StringVar(&assignPath, "path", "", "Project path (defaults to current directory)")
-	assignCmd.Flags().StringVarP(&assignDescription, "description", "d", "", "Description for the port assignment")
+	assignCmd.Flags().StringVarP(&assignDescription, "description", "d", "", "Description for the port assignment; {basename}, {host}, and {date} are expanded; - opens $EDITOR for the description and notes")
+	assignCmd.Flags().BoolVar(&assignNoAutoDesc, "no-auto-description", false, "Leave the description empty instead of using the project directory name")
+	assignCmd.Flags().StringVar(&assignName, "name", "", "Name of the port within its project (e.g. web); must be unique per path")
+	assignCmd.Flags().StringVar(&assignProtocol, "protocol", registry.ProtocolTCP, "Protocol of the port (tcp or udp)")
+	assignCmd.Flags().StringArrayVar(&assignTags, "tag", nil, "Tag for the port assignment (e.g. env:staging); may be repeated")
+	assignCmd.Flags().DurationVar(&assignTTL, "ttl", 0, "Expire the assignment after this long (e.g. 24h) so 'prune --expired' removes it")
+	assignCmd.Flags().StringVar(&assignLabel, "label", "", "Color label to highlight the port with in list (red, green, yellow, blue, magenta, cyan, or white)")
+	assignCmd.Flags().StringVar(&assignNotes, "notes", "", "Longer freeform notes for the port assignment")
+	assignCmd.Flags().StringVar(&assignFromFile, "from-file", "", "Assign the next available port to each path listed in a file (one per line)")
+	assignCmd.Flags().BoolVar(&assignStrict, "strict", false, "Fail instead of warning when the description is already used by another assignment")
+	assignCmd.Flags().BoolVar(&assignFailFast, "fail-fast", false, "Stop at the first path that fails with --from-file")
+	assignCmd.MarkFlagsMutuallyExclusive("from-file", "port")
+	assignCmd.MarkFlagsMutuallyExclusive("from-file", "count")
+	assignCmd.MarkFlagsMutuallyExclusive("from-file", "path")
+	assignCmd.MarkFlagsMutuallyExclusive("name", "count")
+	assignCmd.MarkFlagsMutuallyExclusive("in", "port")
+	assignCmd.MarkFlagsMutuallyExclusive("in", "count")
+	assignCmd.MarkFlagsMutuallyExclusive("in", "start")
+	assignCmd.MarkFlagsMutuallyExclusive("in", "end")
+	assignCmd.MarkFlagsMutuallyExclusive("in", "from-file")
+	assignCmd.Flags().StringVar(&assignFormat, "format", "text", "Output format (text or json)")
 	rootCmd.AddCommand(assignCmd)
 }
\ No newline at end of file
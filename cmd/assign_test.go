@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAssignCount(t *testing.T) {
+	tests := []struct {
+		name         string
+		specificPort bool
+		count        int
+		wantErr      string
+	}{
+		{name: "single port", count: 1},
+		{name: "block", count: 3},
+		{name: "specific port", specificPort: true, count: 1},
+		{name: "zero", count: 0, wantErr: "invalid port count: 0"},
+		{name: "negative", count: -3, wantErr: "invalid port count: -3"},
+		{name: "specific port with zero", specificPort: true, count: 0, wantErr: "invalid port count: 0"},
+		{name: "specific port with block", specificPort: true, count: 2, wantErr: "--port and --count cannot be used together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAssignCount(tt.specificPort, tt.count)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
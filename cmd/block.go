@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blockDescription  string
+	blockForce        bool
+	blockFromServices bool
+	blockServicesFile string
+	blockFromListen   bool
+	blockFromFile     string
+	blockReplace      bool
+	blockCheck        bool
+)
+
+var blockCmd = &cobra.Command{
+	Use:   "block <port|range|pattern>",
+	Short: "Block a port or range of ports",
+	Long: `Block a port, range of ports (e.g. 4000-4010), or pattern (e.g. 40xx or 4*) so
+they are never assigned.
+Blocking ports that are already assigned requires --force.
+
+With --from-services, every named TCP port in /etc/services (or
+--services-file) is blocked instead. Ports that are already blocked are skipped,
+as are assigned ports, with a warning.
+
+With --from-listening, every TCP port that currently has a listener on this
+machine is blocked (Linux only). This is a snapshot: ports that start listening
+later are not blocked.
+
+With --from-file, the entries listed in a file are blocked, one per line as
+"<spec> [description]". Blank lines and lines starting with # are ignored.
+Entries that are already blocked or that cover an assigned port are skipped.
+With --replace, blocked entries not in the file are unblocked, so the registry
+blocks exactly what the file lists.
+
+With --check, nothing is blocked. The assignments the spec covers are listed
+instead, to see what blocking it would conflict with.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if blockFromServices || blockFromListen || blockFromFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if blockReplace && blockFromFile == "" {
+			return errors.New("--replace requires --from-file")
+		}
+		if blockCheck {
+			return checkBlock(args[0])
+		}
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		if blockFromServices {
+			return blockServices(reg, blockServicesFile)
+		}
+		if blockFromListen {
+			return blockListening(reg)
+		}
+		if blockFromFile != "" {
+			return blockFile(reg, blockFromFile)
+		}
+
+		spec := args[0]
+
+		if blockForce {
+			conflicts, err := reg.ForceBlockPorts(spec, blockDescription)
+			if err != nil {
+				return err
+			}
+			for _, a := range conflicts {
+				fmt.Fprintf(os.Stderr, "Warning: port %d is assigned to '%s' but is now blocked\n", a.Port, a.Description)
+			}
+		} else {
+			err = reg.BlockPorts(spec, blockDescription)
+			if err != nil {
+				if errors.Is(err, registry.ErrPortAlreadyAssigned) {
+					return fmt.Errorf("%w. Use --force to block anyway", err)
+				}
+				return err
+			}
+		}
+
+		printInfo("Blocked %s\n", spec)
+		return nil
+	},
+}
+
+// blockServices blocks the named TCP ports of a services(5) file
+func blockServices(reg *registry.Registry, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open services file: %w", err)
+	}
+	defer f.Close()
+
+	services, err := registry.ParseServices(f)
+	if err != nil {
+		return err
+	}
+
+	result, err := reg.MergeBlockedPorts(services)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range result.Assigned {
+		fmt.Fprintf(os.Stderr, "Warning: port %d is assigned to '%s'; not blocked\n", a.Port, a.Description)
+	}
+
+	printInfo("Blocked %d service port(s) (%d already blocked, %d assigned)\n", len(result.Added), len(result.AlreadyBlocked), len(result.Assigned))
+	return nil
+}
+
+// checkBlock lists the assignments that blocking spec would conflict with
+// without changing the registry
+func checkBlock(spec string) error {
+	reg, err := loadRegistry()
+	if err != nil {
+		return err
+	}
+
+	assignments, err := reg.AssignmentsInRange(spec)
+	if err != nil {
+		return err
+	}
+
+	if len(assignments) == 0 {
+		fmt.Printf("No assignments in %s\n", spec)
+		return nil
+	}
+
+	fmt.Printf("Blocking %s would conflict with %d assignment(s):\n", spec, len(assignments))
+	printAssignmentTable(assignments, false)
+	return nil
+}
+
+// blockFile blocks the entries listed in a block list file
+func blockFile(reg *registry.Registry, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open block list: %w", err)
+	}
+	defer f.Close()
+
+	blocked, err := registry.ParseBlockList(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	mode := registry.ImportMerge
+	if blockReplace {
+		mode = registry.ImportReplace
+	}
+
+	result, err := reg.BlockFromList(blocked, mode)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range result.Assigned {
+		fmt.Fprintf(os.Stderr, "Warning: port %d is assigned to '%s'; not blocked\n", a.Port, a.Description)
+	}
+	for _, bp := range result.Removed {
+		printInfo("Unblocked %s\n", bp.Ports)
+	}
+
+	printInfo("Blocked %d entr(ies) (%d already blocked, %d assigned)\n", len(result.Added), len(result.AlreadyBlocked), len(result.Assigned))
+	return nil
+}
+
+// blockListening blocks the TCP ports that currently have a listener. Each is
+// described with the capture time unless --description is given.
+func blockListening(reg *registry.Registry) error {
+	ports, err := registry.ListeningPorts()
+	if err != nil {
+		return err
+	}
+
+	description := blockDescription
+	if description == "" {
+		description = "listening at " + time.Now().UTC().Format(time.RFC3339) + " (snapshot)"
+	}
+
+	blocked := make([]registry.BlockedPort, len(ports))
+	for i, port := range ports {
+		blocked[i] = registry.BlockedPort{Ports: strconv.Itoa(port), Description: description}
+	}
+
+	result, err := reg.MergeBlockedPorts(blocked)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range result.Assigned {
+		fmt.Fprintf(os.Stderr, "Warning: port %d is assigned to '%s'; not blocked\n", a.Port, a.Description)
+	}
+
+	printInfo("Blocked %d listening port(s) (%d already blocked, %d assigned)\n", len(result.Added), len(result.AlreadyBlocked), len(result.Assigned))
+	if !quiet {
+		fmt.Fprintln(os.Stderr, "Note: this is a snapshot; ports that start listening later are not blocked")
+	}
+	return nil
+}
+
+func init() {
+	blockCmd.Flags().StringVarP(&blockDescription, "description", "d", "", "Description for the blocked ports")
+	blockCmd.Flags().BoolVar(&blockForce, "force", false, "Block ports even if some are already assigned")
+	blockCmd.Flags().BoolVar(&blockFromServices, "from-services", false, "Block every named TCP port in the services file")
+	blockCmd.Flags().StringVar(&blockServicesFile, "services-file", "/etc/services", "Services file to read with --from-services")
+	blockCmd.Flags().BoolVar(&blockFromListen, "from-listening", false, "Block every TCP port that currently has a listener on this machine (Linux only)")
+	blockCmd.Flags().StringVar(&blockFromFile, "from-file", "", "Block the entries listed in a file, one \"<spec> [description]\" per line")
+	blockCmd.Flags().BoolVar(&blockReplace, "replace", false, "With --from-file, unblock entries not listed in the file")
+	blockCmd.Flags().BoolVar(&blockCheck, "check", false, "List the assignments the spec covers without blocking anything")
+	blockCmd.MarkFlagsMutuallyExclusive("check", "force")
+	blockCmd.MarkFlagsMutuallyExclusive("check", "from-file")
+	blockCmd.MarkFlagsMutuallyExclusive("check", "from-services")
+	blockCmd.MarkFlagsMutuallyExclusive("check", "from-listening")
+	blockCmd.MarkFlagsMutuallyExclusive("from-services", "force")
+	blockCmd.MarkFlagsMutuallyExclusive("from-file", "force")
+	blockCmd.MarkFlagsMutuallyExclusive("from-file", "from-services")
+	blockCmd.MarkFlagsMutuallyExclusive("from-file", "from-listening")
+	blockCmd.MarkFlagsMutuallyExclusive("from-listening", "force")
+	blockCmd.MarkFlagsMutuallyExclusive("from-listening", "from-services")
+	rootCmd.AddCommand(blockCmd)
+}
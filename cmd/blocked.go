@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blockedFormat    string
+	blockedNormalize bool
+	blockedValidate  bool
+)
+
+var blockedCmd = &cobra.Command{
+	Use:   "blocked",
+	Short: "Display all blocked ports",
+	Long: `Display all blocked ports and ranges in a table or JSON format.
+
+With --normalize, overlapping and adjacent ranges are first merged and the
+registry file is rewritten. With --validate, malformed entries are reported
+instead and the command fails if there are any.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var reg *registry.Registry
+		var err error
+		if blockedNormalize {
+			reg, err = openLockedRegistry()
+			if err != nil {
+				return err
+			}
+			defer reg.Unlock()
+
+			if err := reg.NormalizeBlockedPorts(); err != nil {
+				return err
+			}
+		} else {
+			reg, err = loadRegistry()
+			if err != nil {
+				return err
+			}
+		}
+
+		if blockedValidate {
+			errs := reg.ValidateBlockedPorts()
+			for _, err := range errs {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			if len(errs) > 0 {
+				return fmt.Errorf("found %d invalid blocked entr(ies)", len(errs))
+			}
+			fmt.Println("All blocked entries are valid")
+			return nil
+		}
+
+		blockedPorts := reg.ListBlockedPorts()
+
+		if blockedFormat == "json" {
+			// JSON output
+			if err := printJSON(blockedPorts); err != nil {
+				return err
+			}
+		} else {
+			// Table output
+			if len(blockedPorts) == 0 {
+				fmt.Println("No ports blocked")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PORTS\tDESCRIPTION")
+			fmt.Fprintln(w, "-----\t-----------")
+
+			for _, bp := range blockedPorts {
+				fmt.Fprintf(w, "%s\t%s\n", bp.Ports, bp.Description)
+			}
+
+			w.Flush()
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	blockedCmd.Flags().StringVar(&blockedFormat, "format", "table", "Output format (table or json)")
+	blockedCmd.Flags().BoolVar(&blockedNormalize, "normalize", false, "Merge overlapping and adjacent ranges and rewrite the registry file")
+	blockedCmd.Flags().BoolVar(&blockedValidate, "validate", false, "Check blocked entries for malformed or reversed ranges")
+	blockedCmd.MarkFlagsMutuallyExclusive("normalize", "validate")
+	rootCmd.AddCommand(blockedCmd)
+}
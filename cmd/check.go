@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the registry file for conflicts",
+	Long: `Check the registry file for problems that can be introduced by hand-editing
+it: ports assigned more than once, assigned ports inside a blocked range, and
+malformed blocked ranges. Exits non-zero if any problems are found.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		errs := reg.Validate()
+		if len(errs) == 0 {
+			fmt.Println("No problems found")
+			return nil
+		}
+
+		for _, err := range errs {
+			fmt.Println(err)
+		}
+		return fmt.Errorf("found %d problem(s) in %s", len(errs), registryPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+// completeAssignedPort completes the first argument of a command with the
+// assigned ports, using each description as the completion help text
+func completeAssignedPort(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// PersistentPreRunE does not run for completions, so resolve the registry
+	// path here
+	wd, _ := os.Getwd()
+	path, _ := resolveRegistryPath(registryPath, cmd.Flags().Changed("registry"), os.Getenv(registryEnvVar), wd)
+
+	var reg *registry.Registry
+	var err error
+	if isRemoteRegistry(path) {
+		reg, err = fetchRegistry(path)
+	} else {
+		reg, err = registry.New(path, registryOptions()...)
+	}
+	if err != nil {
+		cobra.CompErrorln(fmt.Sprintf("failed to load registry: %v", err))
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []cobra.Completion
+	for _, a := range reg.ListAssignments() {
+		completions = append(completions, cobra.CompletionWithDesc(strconv.Itoa(a.Port), a.Description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var diffFormat string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <other-registry>",
+	Short: "Compare the registry with another registry file",
+	Long: `Compare the assignments of the registry with another registry file, such as
+the registry of another machine. Prints the ports assigned only in the registry,
+only in the other file, and in both but with a different description or path.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		otherPath := args[0]
+		if _, err := os.Stat(otherPath); err != nil {
+			return fmt.Errorf("failed to load registry: %w", err)
+		}
+		other, err := registry.New(otherPath, registryOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to load registry %s: %w", otherPath, err)
+		}
+
+		diff := reg.Diff(other)
+
+		if diffFormat == "json" {
+			return printJSON(diff)
+		}
+
+		if diff.Empty() {
+			fmt.Println("No differences")
+			return nil
+		}
+
+		sections := []struct {
+			title       string
+			assignments []registry.Assignment
+		}{
+			{"Only in " + registryPath, diff.OnlyInA},
+			{"Only in " + otherPath, diff.OnlyInB},
+		}
+		first := true
+		for _, s := range sections {
+			if len(s.assignments) == 0 {
+				continue
+			}
+			if !first {
+				fmt.Println()
+			}
+			first = false
+			fmt.Printf("%s:\n", s.title)
+			printAssignmentTable(s.assignments, false)
+		}
+
+		if len(diff.Changed) > 0 {
+			if !first {
+				fmt.Println()
+			}
+			fmt.Println("Changed:")
+			printChangeTable(diff.Changed)
+		}
+
+		return nil
+	},
+}
+
+// printChangeTable prints each differing field of changed assignments with
+// its value in both registries
+func printChangeTable(changes []registry.AssignmentChange) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PORT\tFIELD\tTHIS\tOTHER")
+	fmt.Fprintln(w, "----\t-----\t----\t-----")
+	for _, c := range changes {
+		port := strconv.Itoa(c.A.Port)
+		if c.A.Description != c.B.Description {
+			fmt.Fprintf(w, "%s\tdescription\t%s\t%s\n", port, orDash(c.A.Description), orDash(c.B.Description))
+		}
+		if c.A.Path != c.B.Path {
+			fmt.Fprintf(w, "%s\tpath\t%s\t%s\n", port, orDash(c.A.Path), orDash(c.B.Path))
+		}
+	}
+	w.Flush()
+}
+
+// orDash returns s, or "-" if s is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFormat, "format", "table", "Output format (table or json)")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check for stale port assignments",
+	Long: `Check for port assignments whose project path no longer exists. Nothing is
+changed; use 'portreg prune' to unassign them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		stale := reg.StalePaths()
+		if len(stale) == 0 {
+			fmt.Println("No problems found")
+			return nil
+		}
+
+		fmt.Printf("%d assignment(s) with missing paths:\n", len(stale))
+		printAssignmentTable(stale, false)
+		fmt.Println("Use 'portreg prune --yes' to unassign them")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
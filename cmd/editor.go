@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// editDescriptionArg is the --description value that opens an editor
+const editDescriptionArg = "-"
+
+// editorInstructions are appended to the file opened by editDescription
+const editorInstructions = `
+# Enter the description of the port assignment on the first line. Anything
+# after it is saved as the notes. Lines starting with # are ignored, and an
+# empty description aborts the assignment.
+`
+
+// editorCommand returns the editor command from $VISUAL or $EDITOR, or "" if
+// neither is set
+func editorCommand() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	return os.Getenv("EDITOR")
+}
+
+// editDescription opens the user's editor on a temporary file holding notes
+// and returns the description from the first line and the notes from the rest,
+// like git commit does for a message. It fails without an editor or when stdin
+// is not a terminal.
+func editDescription(notes string) (string, string, error) {
+	editor := editorCommand()
+	if editor == "" {
+		return "", "", errors.New("--description - requires $VISUAL or $EDITOR to be set; pass the description instead")
+	}
+	if !isTerminal(os.Stdin) {
+		return "", "", errors.New("--description - requires a terminal; pass the description instead")
+	}
+
+	f, err := os.CreateTemp("", "portreg-description-*.txt")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create description file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	content := "\n"
+	if notes != "" {
+		content += notes + "\n"
+	}
+	content += editorInstructions
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write description file: %w", err)
+	}
+
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", editor+" "+f.Name())
+	} else {
+		// Run through the shell so editors with arguments such as "code --wait" work
+		c = exec.Command("sh", "-c", editor+` "$@"`, "sh", f.Name())
+	}
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	if !isTerminal(os.Stdout) {
+		// Keep the editor on the terminal when the port is being captured
+		c.Stdout = os.Stderr
+	}
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return "", "", fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read description file: %w", err)
+	}
+
+	description, notes := parseEditedDescription(string(data))
+	if description == "" {
+		return "", "", errors.New("aborting assignment due to empty description")
+	}
+	return description, notes, nil
+}
+
+// parseEditedDescription splits edited text into the description on its
+// first non-empty line and the notes after it, ignoring lines starting with #
+func parseEditedDescription(text string) (string, string) {
+	var lines []string
+	for line := range strings.Lines(text) {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+	}
+
+	text = strings.TrimSpace(strings.Join(lines, "\n"))
+	description, notes, _ := strings.Cut(text, "\n")
+	return strings.TrimSpace(description), strings.TrimSpace(notes)
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEditedDescription(t *testing.T) {
+	description, notes := parseEditedDescription("\n  My API  \n\nUsed by the mobile app.\nSee docs.\n" + editorInstructions)
+	assert.Equal(t, "My API", description)
+	assert.Equal(t, "Used by the mobile app.\nSee docs.", notes)
+
+	description, notes = parseEditedDescription("\n" + editorInstructions)
+	assert.Empty(t, description)
+	assert.Empty(t, notes)
+}
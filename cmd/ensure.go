@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ensurePath        string
+	ensureDescription string
+	ensureName        string
+)
+
+var ensureCmd = &cobra.Command{
+	Use:   "ensure",
+	Short: "Print the port of a project, assigning one if needed",
+	Long: `Print the port assigned to a project path (the current directory by default),
+auto-assigning the next available port first if the path has none. With --name
+only the port with that name counts. Running it repeatedly always prints the
+same port, so provisioning scripts can capture it without creating duplicates.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		path := ensurePath
+		if path == "" {
+			path, err = os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+		}
+
+		description, err := expandDescription(ensureDescription, path)
+		if err != nil {
+			return err
+		}
+
+		port, created, err := reg.EnsureAssignment(registry.Assignment{
+			Name:        ensureName,
+			Description: defaultDescription(description, path),
+			Path:        path,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(port)
+		if created {
+			runChangeHook(reg, hookAssign, port)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ensureCmd.Flags().StringVar(&ensurePath, "path", "", "Project path (defaults to current directory)")
+	ensureCmd.Flags().StringVarP(&ensureDescription, "description", "d", "", "Description used if a port is assigned; {basename}, {host}, and {date} are expanded")
+	ensureCmd.Flags().StringVar(&ensureName, "name", "", "Name of the port within its project (e.g. web)")
+	rootCmd.AddCommand(ensureCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envPath string
+	envVar  string
+)
+
+// envVarTagPrefix marks a tag naming the environment variable for an assignment
+const envVarTagPrefix = "var:"
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print shell export lines for a project's ports",
+	Long: `Print an export line for each port assigned to a project path, suitable for
+eval "$(portreg env)". The path defaults to the current directory.
+
+A single port is exported as PORT. Multiple ports are exported as PORT_1,
+PORT_2, ... in port order. The base name can be changed with --var, and an
+assignment tagged var:NAME is always exported as NAME.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		// Use current directory if no path specified
+		path := envPath
+		if path == "" {
+			path, err = os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+		}
+
+		assignments := reg.FindByPath(path)
+		if len(assignments) == 0 {
+			return fmt.Errorf("%w: no ports assigned to %s", registry.ErrPortNotAssigned, path)
+		}
+
+		slices.SortFunc(assignments, func(a, b registry.Assignment) int {
+			return a.Port - b.Port
+		})
+
+		for i, a := range assignments {
+			name := envVar
+			if len(assignments) > 1 {
+				name = fmt.Sprintf("%s_%d", envVar, i+1)
+			}
+			for _, tag := range a.Tags {
+				if v, ok := strings.CutPrefix(tag, envVarTagPrefix); ok && v != "" {
+					name = v
+				}
+			}
+			fmt.Printf("export %s=%d\n", name, a.Port)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	envCmd.Flags().StringVar(&envPath, "path", "", "Project path (defaults to current directory)")
+	envCmd.Flags().StringVar(&envVar, "var", "PORT", "Base name of the exported variable(s)")
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+// firewallFormatters write one firewall rule allowing an assignment's port,
+// keyed by export format
+var firewallFormatters = map[string]func(w io.Writer, a registry.Assignment){
+	"ufw": func(w io.Writer, a registry.Assignment) {
+		fmt.Fprintf(w, "ufw allow %d/%s", a.Port, a.EffectiveProtocol())
+		if d := displayDescription(a); d != "" {
+			fmt.Fprintf(w, " comment %s", shellQuote(d))
+		}
+		fmt.Fprintln(w)
+	},
+	"iptables": func(w io.Writer, a registry.Assignment) {
+		fmt.Fprintf(w, "iptables -A INPUT -p %s --dport %d", a.EffectiveProtocol(), a.Port)
+		if d := displayDescription(a); d != "" {
+			fmt.Fprintf(w, " -m comment --comment %s", shellQuote(d))
+		}
+		fmt.Fprintln(w, " -j ACCEPT")
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print firewall rules allowing the assigned ports",
+	Long: `Print a firewall rule allowing each assigned port, in port order, so the
+registry can be the source of truth for local firewall configuration.
+
+With --format ufw (the default) each rule is a ufw command:
+
+  ufw allow 3100/tcp comment 'web'
+
+With --format iptables each rule is an iptables command:
+
+  iptables -A INPUT -p tcp --dport 3100 -m comment --comment 'web' -j ACCEPT
+
+The descriptions are quoted for the shell, so the output can be reviewed and
+then run with sh. Nothing is changed by this command.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, ok := firewallFormatters[exportFormat]
+		if !ok {
+			return fmt.Errorf("invalid format: %s (must be ufw or iptables)", exportFormat)
+		}
+
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		assignments := reg.ListAssignments()
+		slices.SortStableFunc(assignments, func(a, b registry.Assignment) int {
+			return a.Port - b.Port
+		})
+
+		for _, a := range assignments {
+			format(os.Stdout, a)
+		}
+
+		return nil
+	},
+}
+
+// shellQuote quotes s with single quotes for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "ufw", "Rule format (ufw or iptables)")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var freeCount bool
+
+var freeCmd = &cobra.Command{
+	Use:   "free <port|range>",
+	Short: "List the available ports in a range",
+	Long: `List every port in a port or range (e.g. 8000-8100) that is neither assigned
+nor blocked. With --count, only the number of available ports is printed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, end, err := registry.ParsePortRange(args[0])
+		if err != nil {
+			return err
+		}
+
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		ports := reg.AvailableInRange(start, end)
+
+		if freeCount {
+			fmt.Println(len(ports))
+			return nil
+		}
+
+		for _, port := range ports {
+			fmt.Println(port)
+		}
+		return nil
+	},
+}
+
+func init() {
+	freeCmd.Flags().BoolVar(&freeCount, "count", false, "Print only the number of available ports")
+	rootCmd.AddCommand(freeCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var gcDryRun bool
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove duplicate assignments, merge blocked ranges, and canonicalize paths",
+	Long: `Clean up a long-lived or hand-edited registry file: remove assignments with an
+invalid or missing port, remove blocked entries without ports, remove every
+assignment of a port after the first, merge overlapping and adjacent blocked
+ranges, rewrite project paths in absolute form, and rewrite the file. Relative
+paths are resolved against the current directory. With --dry-run only the
+summary is printed.
+
+gc also works on a file that other commands refuse to load because of
+assignments without a port or blocked entries without ports.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Load leniently so the malformed entries that make a normal load fail
+		// can be removed
+		var reg *registry.Registry
+		var err error
+		if gcDryRun {
+			reg, err = loadRegistry(registry.WithLenientLoad(true))
+			if err != nil {
+				return err
+			}
+		} else {
+			reg, err = openLockedRegistry(registry.WithLenientLoad(true))
+			if err != nil {
+				return err
+			}
+			defer reg.Unlock()
+		}
+
+		// Clean up in memory and save once at the end
+		reg.SetDryRun(true)
+
+		dedupe, err := reg.Dedupe()
+		if err != nil {
+			return err
+		}
+
+		canonicalized, err := reg.CanonicalizePaths()
+		if err != nil {
+			return err
+		}
+
+		blockedBefore := len(reg.ListBlockedPorts())
+		if err := reg.NormalizeBlockedPorts(); err != nil {
+			return err
+		}
+		blockedAfter := len(reg.ListBlockedPorts())
+
+		removed := "Removed"
+		if gcDryRun {
+			removed = "Would remove"
+		}
+		for _, a := range dedupe.Invalid {
+			fmt.Printf("%s invalid assignment of port %d ('%s')\n", removed, a.Port, a.Description)
+		}
+		for _, a := range dedupe.Duplicates {
+			fmt.Printf("%s duplicate assignment of port %d ('%s')\n", removed, a.Port, a.Description)
+		}
+		for _, bp := range dedupe.InvalidBlocked {
+			fmt.Printf("%s blocked entry without ports ('%s')\n", removed, bp.Description)
+		}
+		rewrote := "Rewrote"
+		if gcDryRun {
+			rewrote = "Would rewrite"
+		}
+		printCanonicalized(canonicalized, rewrote)
+		fmt.Printf("Assignments removed: %d\n", dedupe.Removed())
+		fmt.Printf("Paths canonicalized: %d\n", len(canonicalized))
+		fmt.Printf("Blocked entries: %d -> %d\n", blockedBefore, blockedAfter)
+
+		if gcDryRun {
+			fmt.Fprintln(os.Stderr, "Dry run: no changes were saved")
+			return nil
+		}
+
+		reg.SetDryRun(false)
+		return reg.Save()
+	},
+}
+
+// printCanonicalized prints each path changed by Registry.CanonicalizePaths
+// with its new form, after verb
+func printCanonicalized(changed []registry.Assignment, verb string) {
+	for _, a := range changed {
+		canonical, _ := filepath.Abs(a.Path)
+		fmt.Printf("%s path of port %d: %s -> %s\n", verb, a.Port, a.Path, canonical)
+	}
+}
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Print what would be cleaned up without changing anything")
+	rootCmd.AddCommand(gcCmd)
+}
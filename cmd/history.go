@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyFormat string
+	historyPort   int
+	historyLimit  int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the audit log of assignment changes",
+	Long: `Show the audit log of assign, unassign, and move operations, oldest first.
+
+The audit log is only written when the registry file sets "audit": true or
+--audit-log is given. It is stored next to the registry file with a .log
+extension unless --audit-log names another file.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isRemoteRegistry(registryPath) && auditLog == "" {
+			return fmt.Errorf("remote registry %s has no audit log; use --audit-log to read a local one", registryPath)
+		}
+
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		entries, err := reg.History()
+		if err != nil {
+			return err
+		}
+
+		if cmd.Flags().Changed("port") {
+			filtered := []registry.AuditEntry{}
+			for _, e := range entries {
+				if e.Port == historyPort || e.From == historyPort {
+					filtered = append(filtered, e)
+				}
+			}
+			entries = filtered
+		}
+
+		if historyLimit > 0 && len(entries) > historyLimit {
+			entries = entries[len(entries)-historyLimit:]
+		}
+
+		if historyFormat == "json" {
+			return printJSON(entries)
+		}
+
+		if len(entries) == 0 {
+			if !reg.AuditEnabled() {
+				fmt.Println(`No history recorded (set "audit": true in the registry file to enable the audit log)`)
+			} else {
+				fmt.Println("No history recorded")
+			}
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tOPERATION\tPORT\tDESCRIPTION\tUSER")
+		fmt.Fprintln(w, "----\t---------\t----\t-----------\t----")
+
+		for _, e := range entries {
+			port := fmt.Sprint(e.Port)
+			if e.Operation == registry.AuditMove {
+				port = fmt.Sprintf("%d -> %d", e.From, e.Port)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(time.DateTime), e.Operation, port, e.Description, e.User)
+		}
+
+		w.Flush()
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyFormat, "format", "table", "Output format (table or json)")
+	historyCmd.Flags().IntVarP(&historyPort, "port", "p", 0, "Only show changes to this port")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 0, "Only show the most recent N changes")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/portreg/registry"
+)
+
+// Operations passed to the onChange hook
+const (
+	hookAssign   = "assign"
+	hookUnassign = "unassign"
+)
+
+// runChangeHook runs the registry's onChange command once for each port as
+// "<command> <port> <operation>" in the directory of the registry file. The
+// registry lock is released first so the hook may run portreg itself. Hook
+// failures are printed to stderr as warnings and never fail the command.
+//
+// The command comes from the registry file, so it only runs when the user opts
+// in with --run-hooks or PORTREG_RUN_HOOKS, and never for a registry
+// discovered in the current directory or its ancestors: a cloned repository
+// could otherwise run arbitrary commands.
+func runChangeHook(reg *registry.Registry, operation string, ports ...int) {
+	hook := reg.OnChange()
+	if hook == "" || len(ports) == 0 {
+		return
+	}
+	if !runHooks {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "Not running onChange hook; use --run-hooks to run it")
+		}
+		return
+	}
+	if registryDiscovered {
+		fmt.Fprintf(os.Stderr, "Warning: not running onChange hook of discovered registry %s; select it with --registry or %s to run it\n", registryPath, registryEnvVar)
+		return
+	}
+
+	reg.Unlock()
+
+	for _, port := range ports {
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.Command("cmd", "/C", hook+" "+strconv.Itoa(port)+" "+operation)
+		} else {
+			c = exec.Command("sh", "-c", hook+` "$@"`, "sh", strconv.Itoa(port), operation)
+		}
+		c.Dir = filepath.Dir(registryPath)
+
+		output, err := c.CombinedOutput()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: onChange hook failed for %s of port %d: %v\n", operation, port, err)
+			if out := strings.TrimSpace(string(output)); out != "" {
+				fmt.Fprintln(os.Stderr, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importReplace bool
+	importForce   bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import port assignments from a JSON file",
+	Long: `Import port assignments from a JSON file. The file may contain an array of
+assignments (as written by 'portreg list --format json') or a registry file.
+
+By default assignments are merged with the existing assignments. If any
+imported port is already assigned or blocked nothing is imported unless --force
+is given, in which case the conflicting assignments are skipped. With --replace
+all existing assignments are replaced.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		assignments, err := readImportFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		mode := registry.ImportMerge
+		if importReplace {
+			mode = registry.ImportReplace
+		}
+
+		err = reg.Import(assignments, mode)
+		var importErr *registry.ImportError
+		if errors.As(err, &importErr) && importForce {
+			skip := make(map[int]bool)
+			for _, c := range importErr.Conflicts {
+				fmt.Fprintf(os.Stderr, "Skipping: %v\n", c.Err)
+				skip[c.Index] = true
+			}
+
+			remaining := make([]registry.Assignment, 0, len(assignments))
+			for i, a := range assignments {
+				if !skip[i] {
+					remaining = append(remaining, a)
+				}
+			}
+			assignments = remaining
+
+			err = reg.Import(assignments, mode)
+		}
+		if err != nil {
+			if errors.As(err, &importErr) {
+				for _, c := range importErr.Conflicts {
+					fmt.Fprintf(os.Stderr, "Conflict: %v\n", c.Err)
+				}
+				return fmt.Errorf("import failed with %d conflict(s). Use --force to skip them", len(importErr.Conflicts))
+			}
+			return err
+		}
+
+		printInfo("Imported %d assignment(s)\n", len(assignments))
+		return nil
+	},
+}
+
+// readImportFile reads assignments from a JSON array or registry file
+func readImportFile(path string) ([]registry.Assignment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	var assignments []registry.Assignment
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &assignments)
+	} else {
+		var file struct {
+			Assignments []registry.Assignment `json:"assignments"`
+		}
+		err = json.Unmarshal(data, &file)
+		assignments = file.Assignments
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse import file: %w", err)
+	}
+
+	return assignments, nil
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importReplace, "replace", false, "Replace all existing assignments")
+	importCmd.Flags().BoolVar(&importForce, "force", false, "Skip conflicting assignments instead of aborting")
+	rootCmd.AddCommand(importCmd)
+}
@@ -1,31 +1,89 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/jackc/portreg/registry"
 	"github.com/spf13/cobra"
 )
 
+var (
+	initNoDefaults   bool
+	initDefaultsFile string
+)
+
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize the registry file",
-	Long:  `Initialize a new registry file with default blocked ports for common services.`,
+	Long: `Initialize a new registry file with default blocked ports for common services.
+Use --no-defaults to create an empty registry or --defaults-file to seed the
+blocked ports from a file instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		reg, err := registry.New(registryPath)
+		if err := checkWritable(); err != nil {
+			return err
+		}
+
+		reg, err := registry.New(registryPath, registryOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create registry: %w", err)
 		}
 
-		if err := reg.Init(); err != nil {
+		if err := reg.Lock(); err != nil {
 			return err
 		}
+		defer reg.Unlock()
+		applyFileMode(reg)
+
+		blocked := registry.DefaultBlockedPorts()
+		if initNoDefaults {
+			blocked = nil
+		} else if initDefaultsFile != "" {
+			blocked, err = readBlockedPortsFile(initDefaultsFile)
+			if err != nil {
+				return err
+			}
+		}
 
-		fmt.Printf("Initialized registry at %s\n", registryPath)
+		if err := reg.InitWith(blocked); err != nil {
+			return err
+		}
+
+		printInfo("Initialized registry at %s\n", registryPath)
 		return nil
 	},
 }
 
+// readBlockedPortsFile reads blocked ports from a JSON file containing either an
+// array of blocked entries or a registry object with a blockedPorts key
+func readBlockedPortsFile(path string) ([]registry.BlockedPort, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read defaults file: %w", err)
+	}
+
+	var blocked []registry.BlockedPort
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &blocked)
+	} else {
+		var file struct {
+			BlockedPorts []registry.BlockedPort `json:"blockedPorts"`
+		}
+		err = json.Unmarshal(data, &file)
+		blocked = file.BlockedPorts
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse defaults file: %w", err)
+	}
+
+	return blocked, nil
+}
+
 func init() {
+	initCmd.Flags().BoolVar(&initNoDefaults, "no-defaults", false, "Create the registry without any blocked ports")
+	initCmd.Flags().StringVar(&initDefaultsFile, "defaults-file", "", "Seed blocked ports from a JSON file instead of the built-in defaults")
+	initCmd.MarkFlagsMutuallyExclusive("no-defaults", "defaults-file")
 	rootCmd.AddCommand(initCmd)
 }
\ No newline at end of file
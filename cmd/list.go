@@ -1,63 +1,392 @@
 package cmd
 
 import (
-	"encoding/json"
+	"encoding/csv"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/jackc/portreg/registry"
 	"github.com/spf13/cobra"
 )
 
-var listFormat string
+var (
+	listFormat    string
+	listCheck     bool
+	listSort      string
+	listTag       string
+	listPorcelain bool
+	listSince     string
+	listBefore    string
+	listCompact   bool
+	listGroupBy   string
+	listFree      bool
+	listRange     string
+)
+
+// listEnvelopeVersion is the version of the list --format json-full envelope.
+// Increment it when the envelope changes incompatibly.
+const listEnvelopeVersion = 1
+
+// listEnvelope is the output of list --format json-full: the assignments with
+// metadata about where and when they were read
+type listEnvelope struct {
+	Version      int                   `json:"version"`
+	GeneratedAt  time.Time             `json:"generatedAt"`
+	RegistryPath string                `json:"registryPath"`
+	Assignments  []registry.Assignment `json:"assignments"`
+}
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Display all assigned ports",
-	Long:  `Display all assigned ports in a table or JSON format.`,
+	Long: `Display all assigned ports in a table, JSON, JSON Lines, or CSV format.
+
+--format json prints an array of assignments. --format json-full wraps the
+array in an object with the envelope version, the time the output was
+generated, and the absolute path of the registry file.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		reg, err := registry.New(registryPath)
+		reg, err := loadRegistry()
 		if err != nil {
-			return fmt.Errorf("failed to load registry: %w", err)
+			return err
+		}
+
+		if listFree {
+			return listFreeRanges(reg)
+		}
+		if listRange != "" {
+			return fmt.Errorf("--range can only be used with --free")
 		}
 
 		assignments := reg.ListAssignments()
+		if listSince != "" || listBefore != "" {
+			now := time.Now()
+			since, err := parseTimeFilter(listSince, now)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			before, err := parseTimeFilter(listBefore, now)
+			if err != nil {
+				return fmt.Errorf("invalid --before: %w", err)
+			}
 
-		if listFormat == "json" {
-			// JSON output
-			data, err := json.MarshalIndent(assignments, "", "  ")
+			assignments = reg.FilterByTime(since, before)
+
+			var untimed int
+			for _, a := range reg.ListAssignments() {
+				if a.CreatedAt.IsZero() {
+					untimed++
+				}
+			}
+			if untimed > 0 {
+				fmt.Fprintf(os.Stderr, "Note: %d assignment(s) without a creation time were excluded\n", untimed)
+			}
+		}
+		if listTag != "" {
+			assignments = slices.DeleteFunc(assignments, func(a registry.Assignment) bool {
+				return !slices.Contains(a.Tags, listTag)
+			})
+		}
+
+		assignments, err = sortAssignments(assignments, listSort)
+		if err != nil {
+			return err
+		}
+
+		if listGroupBy != "none" {
+			groups, err := groupAssignments(assignments, listGroupBy)
 			if err != nil {
-				return fmt.Errorf("failed to marshal JSON: %w", err)
+				return err
 			}
-			fmt.Println(string(data))
-		} else {
-			// Table output
-			if len(assignments) == 0 {
-				fmt.Println("No ports assigned")
-				return nil
+			return printAssignmentGroups(groups)
+		}
+
+		if listPorcelain {
+			// Tab-separated output for scripts
+			printAssignmentPorcelain(assignments, listCheck)
+			return nil
+		}
+
+		if listFormat == "json" {
+			// JSON output
+			if listCompact {
+				return printCompactJSON(withProtocols(assignments))
 			}
+			return printJSON(withProtocols(assignments))
+		}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			fmt.Fprintln(w, "PORT\tDESCRIPTION\tPATH")
-			fmt.Fprintln(w, "----\t-----------\t----")
+		if listFormat == "json-full" {
+			// JSON output with metadata
+			path := registryPath
+			if !isRemoteRegistry(path) {
+				path, err = filepath.Abs(path)
+				if err != nil {
+					return fmt.Errorf("failed to resolve registry path: %w", err)
+				}
+			}
+			envelope := listEnvelope{
+				Version:      listEnvelopeVersion,
+				GeneratedAt:  time.Now().UTC().Truncate(time.Second),
+				RegistryPath: path,
+				Assignments:  withProtocols(assignments),
+			}
+			if listCompact {
+				return printCompactJSON(envelope)
+			}
+			return printJSON(envelope)
+		}
 
-			for _, a := range assignments {
-				path := a.Path
-				if path == "" {
-					path = "-"
+		if listFormat == "jsonl" {
+			// JSON Lines output
+			for _, a := range withProtocols(assignments) {
+				if err := printCompactJSON(a); err != nil {
+					return err
 				}
-				fmt.Fprintf(w, "%d\t%s\t%s\n", a.Port, a.Description, path)
 			}
+			return nil
+		}
 
-			w.Flush()
+		if listFormat == "csv" {
+			// CSV output
+			return printAssignmentCSV(assignments)
 		}
 
+		// Table output
+		if len(assignments) == 0 {
+			fmt.Println("No ports assigned")
+			return nil
+		}
+
+		printAssignmentTable(assignments, listCheck)
 		return nil
 	},
 }
 
+// listFreeRanges prints the runs of available ports within --range, or between
+// the lowest and highest assigned ports
+func listFreeRanges(reg *registry.Registry) error {
+	var start, end int
+	if listRange != "" {
+		var err error
+		start, end, err = registry.ParsePortRange(listRange)
+		if err != nil {
+			return err
+		}
+	} else {
+		stats := reg.Stats()
+		if stats.AssignmentCount == 0 {
+			fmt.Println("No ports assigned. Use --range to list free ranges")
+			return nil
+		}
+		start, end = stats.LowestPort, stats.HighestPort
+	}
+
+	ranges := reg.FreeRanges(start, end)
+	switch listFormat {
+	case "json":
+		if listCompact {
+			return printCompactJSON(ranges)
+		}
+		return printJSON(ranges)
+	case "table":
+	default:
+		return fmt.Errorf("--free does not support --format %s", listFormat)
+	}
+
+	if len(ranges) == 0 {
+		fmt.Printf("No free ports between %d and %d\n", start, end)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "START\tEND\tSIZE")
+	fmt.Fprintln(w, "-----\t---\t----")
+	for _, pr := range ranges {
+		fmt.Fprintf(w, "%d\t%d\t%d\n", pr.Start, pr.End, pr.End-pr.Start+1)
+	}
+	return w.Flush()
+}
+
+// sortAssignments returns a sorted copy of assignments. by is one of "port",
+// "description", or "created" (oldest first, with assignments that have no
+// creation time before all others).
+func sortAssignments(assignments []registry.Assignment, by string) ([]registry.Assignment, error) {
+	sorted := slices.Clone(assignments)
+
+	switch by {
+	case "port":
+		slices.SortStableFunc(sorted, func(a, b registry.Assignment) int {
+			return a.Port - b.Port
+		})
+	case "description":
+		slices.SortStableFunc(sorted, func(a, b registry.Assignment) int {
+			if c := strings.Compare(strings.ToLower(a.Description), strings.ToLower(b.Description)); c != 0 {
+				return c
+			}
+			return a.Port - b.Port
+		})
+	case "created":
+		slices.SortStableFunc(sorted, func(a, b registry.Assignment) int {
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c
+			}
+			return a.Port - b.Port
+		})
+	default:
+		return nil, fmt.Errorf("invalid sort: %s (must be port, description, or created)", by)
+	}
+
+	return sorted, nil
+}
+
+// assignmentGroup is a set of assignments sharing a path or description
+type assignmentGroup struct {
+	key         string
+	assignments []registry.Assignment
+}
+
+// groupAssignments buckets assignments by "path" or "description". Groups are
+// sorted by key with the empty key last, and each group keeps the order of
+// assignments.
+func groupAssignments(assignments []registry.Assignment, by string) ([]assignmentGroup, error) {
+	var key func(registry.Assignment) string
+	switch by {
+	case "path":
+		key = func(a registry.Assignment) string { return a.Path }
+	case "description":
+		key = func(a registry.Assignment) string { return a.Description }
+	default:
+		return nil, fmt.Errorf("invalid group-by: %s (must be none, path, or description)", by)
+	}
+
+	var groups []assignmentGroup
+	index := make(map[string]int)
+	for _, a := range assignments {
+		k := key(a)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, assignmentGroup{key: k})
+		}
+		groups[i].assignments = append(groups[i].assignments, a)
+	}
+
+	slices.SortFunc(groups, func(a, b assignmentGroup) int {
+		if (a.key == "") != (b.key == "") {
+			if a.key == "" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.key, b.key)
+	})
+
+	return groups, nil
+}
+
+// printAssignmentGroups writes groups in the --format of the list command.
+// JSON output is an object keyed by group.
+func printAssignmentGroups(groups []assignmentGroup) error {
+	switch listFormat {
+	case "json":
+		byKey := make(map[string][]registry.Assignment, len(groups))
+		for _, g := range groups {
+			byKey[g.key] = withProtocols(g.assignments)
+		}
+		if listCompact {
+			return printCompactJSON(byKey)
+		}
+		return printJSON(byKey)
+	case "table":
+	default:
+		return fmt.Errorf("--group-by does not support --format %s", listFormat)
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No ports assigned")
+		return nil
+	}
+
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		heading := g.key
+		if heading == "" {
+			heading = "(none)"
+		}
+		fmt.Printf("%s:\n", heading)
+		printAssignmentTable(g.assignments, listCheck)
+	}
+	return nil
+}
+
+// parseTimeFilter parses a --since or --before value relative to now. It
+// accepts an RFC 3339 timestamp, a date (2006-01-02, local time), or a duration
+// ago such as 12h or 7d. An empty value returns the zero time.
+func parseTimeFilter(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a timestamp, date, or duration such as 7d or 12h", s)
+}
+
+// printAssignmentCSV writes assignments to stdout as RFC 4180 CSV
+func printAssignmentCSV(assignments []registry.Assignment) error {
+	w := csv.NewWriter(os.Stdout)
+	w.UseCRLF = true
+
+	if err := w.Write([]string{"port", "description", "path"}); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, a := range assignments {
+		if err := w.Write([]string{strconv.Itoa(a.Port), a.Description, a.Path}); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
 func init() {
-	listCmd.Flags().StringVar(&listFormat, "format", "table", "Output format (table or json)")
+	listCmd.Flags().StringVar(&listFormat, "format", "table", "Output format (table, json, json-full, jsonl, or csv)")
+	listCmd.Flags().StringVar(&listSort, "sort", "port", "Sort order (port, description, or created)")
+	listCmd.Flags().StringVar(&listTag, "tag", "", "Only show assignments with this tag")
+	listCmd.Flags().StringVar(&listSince, "since", "", "Only show assignments created at or after this time (RFC 3339, date, or duration ago such as 7d)")
+	listCmd.Flags().StringVar(&listBefore, "before", "", "Only show assignments created before this time (RFC 3339, date, or duration ago such as 7d)")
+	listCmd.Flags().StringVar(&listGroupBy, "group-by", "none", "Group assignments under a heading per path or description (none, path, or description)")
+	listCmd.Flags().BoolVar(&listFree, "free", false, "List the ranges of free ports between the lowest and highest assigned ports instead of assignments")
+	listCmd.Flags().StringVar(&listRange, "range", "", "Port or range to list free ranges in (with --free)")
+	listCmd.Flags().BoolVar(&listCheck, "check", false, "Show whether each assigned port currently has a listener")
+	listCmd.Flags().BoolVar(&listCompact, "compact", false, "Print JSON on a single line without indentation (with --format json or json-full)")
+	listCmd.Flags().BoolVar(&listPorcelain, "porcelain", false, "Print stable tab-separated fields with no header for scripts")
+	listCmd.MarkFlagsMutuallyExclusive("porcelain", "format")
+	listCmd.MarkFlagsMutuallyExclusive("porcelain", "group-by")
+	listCmd.MarkFlagsMutuallyExclusive("porcelain", "free")
+	listCmd.MarkFlagsMutuallyExclusive("group-by", "free")
 	rootCmd.AddCommand(listCmd)
-}
\ No newline at end of file
+}
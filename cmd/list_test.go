@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeFilter(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"empty", "", time.Time{}},
+		{"RFC 3339", "2024-03-01T08:30:00Z", time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)},
+		{"RFC 3339 with offset", "2024-03-01T08:30:00+02:00", time.Date(2024, 3, 1, 6, 30, 0, 0, time.UTC)},
+		{"date", "2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+		{"days", "7d", time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)},
+		{"zero days", "0d", now},
+		{"duration", "12h", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"compound duration", "1h30m", time.Date(2024, 3, 10, 10, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimeFilter(tt.value, now)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v, want %v", got, tt.want)
+		})
+	}
+
+	for _, value := range []string{"yesterday", "-7d", "-1h", "2024-13-01", "7w"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			_, err := parseTimeFilter(value, now)
+			assert.Error(t, err)
+		})
+	}
+}
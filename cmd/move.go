@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var moveProtocol string
+
+var moveCmd = &cobra.Command{
+	Use:               "move <from> <to>",
+	Short:             "Move a port assignment to a different port",
+	Long:              `Move a port assignment to a different port, preserving its description and path.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAssignedPort,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid port number: %s", args[0])
+		}
+		to, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid port number: %s", args[1])
+		}
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		err = reg.MoveProtocol(from, to, moveProtocol)
+		if err != nil {
+			if errors.Is(err, registry.ErrPortAlreadyAssigned) {
+				return fmt.Errorf("%w. Use 'portreg list' to see all assignments", err)
+			}
+			return assignmentLookupError(err)
+		}
+
+		printInfo("Moved port %d to %d\n", from, to)
+		return nil
+	},
+}
+
+func init() {
+	moveCmd.Flags().StringVar(&moveProtocol, "protocol", "", "Protocol of the assignment to move (tcp or udp); required if the port is assigned for both")
+	rootCmd.AddCommand(moveCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	nextStart  int
+	nextEnd    int
+	nextFormat string
+)
+
+var nextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Print the next available port without assigning it",
+	Long: `Print the port that would be assigned next by 'portreg assign' without
+assigning it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		if nextStart > 0 {
+			reg.SetStartPort(nextStart)
+		}
+		if nextEnd > 0 {
+			reg.SetEndPort(nextEnd)
+		}
+
+		port, err := reg.NextAvailable()
+		if err != nil {
+			return err
+		}
+
+		if nextFormat == "json" {
+			return printCompactJSON(struct {
+				Port int `json:"port"`
+			}{port})
+		}
+
+		fmt.Println(port)
+		return nil
+	},
+}
+
+func init() {
+	nextCmd.Flags().IntVar(&nextStart, "start", 0, "Port to start from (overrides registry start port)")
+	nextCmd.Flags().IntVar(&nextEnd, "end", 0, "Last port to consider (overrides registry end port)")
+	nextCmd.Flags().StringVar(&nextFormat, "format", "text", "Output format (text or json)")
+	rootCmd.AddCommand(nextCmd)
+}
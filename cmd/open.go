@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	openPath  string
+	openName  string
+	openPrint bool
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open",
+	Short: "Open a project's port in the browser",
+	Long: `Open http://localhost:<port> in the browser for the port assigned to a project
+path. The path defaults to the current directory. If several ports are assigned
+to the path, select one with --name or choose one when asked.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		// Use current directory if no path specified
+		path := openPath
+		if path == "" {
+			path, err = os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+		}
+
+		assignments := reg.FindByPath(path)
+		if openName != "" {
+			assignments = slices.DeleteFunc(assignments, func(a registry.Assignment) bool {
+				return a.Name != openName
+			})
+		}
+		if len(assignments) == 0 {
+			if openName != "" {
+				return fmt.Errorf("%w: no port named %s assigned to %s", registry.ErrPortNotAssigned, openName, path)
+			}
+			return fmt.Errorf("%w: no ports assigned to %s", registry.ErrPortNotAssigned, path)
+		}
+
+		slices.SortFunc(assignments, func(a, b registry.Assignment) int {
+			return a.Port - b.Port
+		})
+
+		a := assignments[0]
+		if len(assignments) > 1 {
+			if !isTerminal(os.Stdin) {
+				return fmt.Errorf("%d ports are assigned to %s. Use --name to select one", len(assignments), path)
+			}
+
+			options := make([]string, len(assignments))
+			for i, a := range assignments {
+				options[i] = strconv.Itoa(a.Port)
+				if a.Name != "" {
+					options[i] += " " + a.Name
+				}
+				options[i] += " (" + displayDescription(a) + ")"
+			}
+			i, err := choose("Which port should be opened?", options)
+			if err != nil {
+				return err
+			}
+			a = assignments[i]
+		}
+
+		url := fmt.Sprintf("http://localhost:%d", a.Port)
+		if openPrint {
+			fmt.Println(url)
+			return nil
+		}
+		return openBrowser(url)
+	},
+}
+
+// openBrowser opens url with the operating system's default handler
+func openBrowser(url string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", url)
+	case "windows":
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		c = exec.Command("xdg-open", url)
+	}
+
+	// Do not wait: some openers only return once the browser exits
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	return nil
+}
+
+func init() {
+	openCmd.Flags().StringVar(&openPath, "path", "", "Project path (defaults to current directory)")
+	openCmd.Flags().StringVar(&openName, "name", "", "Name of the port to open when several are assigned to the path")
+	openCmd.Flags().BoolVar(&openPrint, "print", false, "Print the URL instead of opening it")
+	rootCmd.AddCommand(openCmd)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jackc/portreg/registry"
+)
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printCompactJSON writes v to stdout as JSON on a single line
+func printCompactJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// ANSI SGR codes used when color is enabled. Codes applied to the same table
+// column have the same length (see colorize).
+const (
+	colorReset   = "0"
+	colorDim     = "2"
+	colorCyan    = "36"
+	colorDefault = "39"
+)
+
+// labelColors maps assignment labels to the ANSI SGR codes ports with that
+// label are colored with. Every code has the length of colorCyan.
+var labelColors = map[string]string{
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+}
+
+// portColor returns the SGR code to color the port of a with: its label's
+// color, or colorCyan for empty and unknown labels
+func portColor(a registry.Assignment) string {
+	if code, ok := labelColors[strings.ToLower(a.Label)]; ok {
+		return code
+	}
+	return colorCyan
+}
+
+// useColor reports whether output should be colored: stdout must be a terminal
+// and NO_COLOR must not be set.
+func useColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// colorize wraps s in the ANSI SGR code. tabwriter counts escape sequences as
+// text, so every cell of a column, including the header, must be wrapped with
+// a code of the same length for the column to stay aligned.
+func colorize(code, s string) string {
+	return "\x1b[" + code + "m" + s + "\x1b[" + colorReset + "m"
+}
+
+// printAssignmentTable writes assignments to stdout as a table. If check is
+// true a STATUS column shows whether each port currently has a listener. When
+// stdout is a terminal, ports are colored (by label if they have a known one)
+// and missing paths are dimmed.
+func printAssignmentTable(assignments []registry.Assignment, check bool) {
+	color := useColor()
+	cell := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return colorize(code, s)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, header := range [][]string{{"PORT", "DESCRIPTION", "PATH", "STATUS"}, {"----", "-----------", "----", "------"}} {
+		if check {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cell(colorDefault, header[0]), header[1], cell(colorReset, header[2]), header[3])
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", cell(colorDefault, header[0]), header[1], cell(colorReset, header[2]))
+		}
+	}
+
+	for _, a := range assignments {
+		port := cell(portColor(a), strconv.Itoa(a.Port))
+		path := cell(colorReset, a.Path)
+		if a.Path == "" {
+			path = cell(colorDim, "-")
+		}
+		description := displayDescription(a)
+		if check {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", port, description, path, listenStatus(a))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", port, description, path)
+		}
+	}
+
+	w.Flush()
+}
+
+// printAssignmentPorcelain writes assignments to stdout as stable
+// tab-separated port, description, and path fields with no header. If check
+// is true a status field is added.
+func printAssignmentPorcelain(assignments []registry.Assignment, check bool) {
+	for _, a := range assignments {
+		if check {
+			fmt.Printf("%d\t%s\t%s\t%s\n", a.Port, a.Description, a.Path, listenStatus(a))
+		} else {
+			fmt.Printf("%d\t%s\t%s\n", a.Port, a.Description, a.Path)
+		}
+	}
+}
+
+// listenStatus returns "in use" if the port of a currently has a listener for
+// its protocol and "free" otherwise
+func listenStatus(a registry.Assignment) string {
+	if registry.CheckListening(a.Port, a.EffectiveProtocol()) {
+		return "in use"
+	}
+	return "free"
+}
+
+// withProtocols returns a copy of assignments with the default protocol filled
+// in so JSON output always includes it
+func withProtocols(assignments []registry.Assignment) []registry.Assignment {
+	filled := make([]registry.Assignment, len(assignments))
+	for i, a := range assignments {
+		a.Protocol = a.EffectiveProtocol()
+		filled[i] = a
+	}
+	return filled
+}
+
+// displayDescription returns the description of an assignment for display,
+// marking reserved ports
+func displayDescription(a registry.Assignment) string {
+	if !a.Reserved {
+		return a.Description
+	}
+	if a.Description == "" {
+		return "(reserved)"
+	}
+	return a.Description + " (reserved)"
+}
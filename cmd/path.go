@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var pathFormat string
+
+var pathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Display the registry file in use",
+	Long: `Display the absolute path of the registry file in use after applying
+--registry, PORTREG_REGISTRY, and discovery, whether it exists, and how many
+assignments it contains. The registry is never modified.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := struct {
+			Path        string `json:"path"`
+			Exists      bool   `json:"exists"`
+			Assignments int    `json:"assignments"`
+		}{Path: registryPath}
+
+		if isRemoteRegistry(registryPath) {
+			// A URL is shown as is; it exists if it can be fetched
+			reg, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+			info.Exists = true
+			info.Assignments = len(reg.ListAssignments())
+		} else {
+			path, err := filepath.Abs(registryPath)
+			if err != nil {
+				return fmt.Errorf("failed to resolve registry path: %w", err)
+			}
+			info.Path = path
+
+			if _, err := os.Stat(path); err == nil {
+				info.Exists = true
+
+				reg, err := registry.New(path, registryOptions()...)
+				if err != nil {
+					return fmt.Errorf("failed to load registry: %w", err)
+				}
+				info.Assignments = len(reg.ListAssignments())
+			}
+		}
+
+		if pathFormat == "json" {
+			return printJSON(info)
+		}
+
+		exists := "no"
+		if info.Exists {
+			exists = "yes"
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Path:\t%s\n", info.Path)
+		fmt.Fprintf(w, "Exists:\t%s\n", exists)
+		fmt.Fprintf(w, "Assignments:\t%d\n", info.Assignments)
+		return w.Flush()
+	},
+}
+
+func init() {
+	pathCmd.Flags().StringVar(&pathFormat, "format", "table", "Output format (table or json)")
+	rootCmd.AddCommand(pathCmd)
+}
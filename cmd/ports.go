@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var portsFormat string
+
+var portsCmd = &cobra.Command{
+	Use:   "ports [path]",
+	Short: "Display the named ports of a project",
+	Long: `Display every port assigned to a project path together with its name. The
+path defaults to the current directory.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		var path string
+		if len(args) > 0 {
+			path = args[0]
+		} else {
+			path, err = os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+		}
+
+		assignments := reg.FindByPath(path)
+		slices.SortFunc(assignments, func(a, b registry.Assignment) int {
+			return a.Port - b.Port
+		})
+
+		if portsFormat == "json" {
+			return printJSON(assignments)
+		}
+
+		if len(assignments) == 0 {
+			fmt.Printf("No ports assigned to %s\n", path)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tPORT\tDESCRIPTION")
+		for _, a := range assignments {
+			name := a.Name
+			if name == "" {
+				name = "-"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\n", name, a.Port, displayDescription(a))
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	portsCmd.Flags().StringVar(&portsFormat, "format", "table", "Output format (table or json)")
+	rootCmd.AddCommand(portsCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// isTerminal reports whether f is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin.
+// Anything other than y or yes is a no.
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("no answer read from stdin (use --yes when not running interactively): %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// choose lists numbered options on stderr and reads the number of the chosen
+// one from stdin. It returns the index of the chosen option.
+func choose(question string, options []string) (int, error) {
+	for i, option := range options {
+		fmt.Fprintf(os.Stderr, "%d) %s\n", i+1, option)
+	}
+	fmt.Fprintf(os.Stderr, "%s [1-%d] ", question, len(options))
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return 0, fmt.Errorf("no answer read from stdin: %w", err)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(options) {
+		return 0, fmt.Errorf("invalid choice: %s", strings.TrimSpace(answer))
+	}
+	return n - 1, nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneDryRun  bool
+	pruneYes     bool
+	pruneExpired bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Unassign ports whose project path no longer exists",
+	Long: `Unassign ports whose project path no longer exists, or with --expired, ports
+whose expiry (set with 'assign --ttl') has passed. Without --yes the
+assignments are only listed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pruneDryRun || !pruneYes {
+			reg, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			stale := reg.StalePaths()
+			if pruneExpired {
+				stale = reg.ExpiredAssignments()
+			}
+			if len(stale) == 0 {
+				fmt.Println("Nothing to prune")
+				return nil
+			}
+
+			fmt.Printf("Would unassign %d port(s):\n", len(stale))
+			printAssignmentTable(stale, false)
+			if !pruneDryRun {
+				fmt.Println("Use --yes to unassign them")
+			}
+			return nil
+		}
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		prune := reg.PruneStalePaths
+		if pruneExpired {
+			prune = reg.PruneExpired
+		}
+
+		removed, err := prune()
+		if err != nil {
+			return err
+		}
+
+		for _, a := range removed {
+			printInfo("Unassigned port %d\n", a.Port)
+		}
+		if len(removed) == 0 {
+			printInfo("Nothing to prune\n")
+		}
+		return nil
+	},
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "List the assignments that would be unassigned without changing anything")
+	pruneCmd.Flags().BoolVar(&pruneYes, "yes", false, "Unassign without asking")
+	pruneCmd.Flags().BoolVar(&pruneExpired, "expired", false, "Unassign expired assignments instead of those with a missing path")
+	rootCmd.AddCommand(pruneCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/portreg/registry"
+)
+
+// remoteTimeout limits how long fetching a remote registry may take
+const remoteTimeout = 10 * time.Second
+
+// maxRemoteSize is the largest remote registry that is read
+const maxRemoteSize = 64 << 20
+
+// isRemoteRegistry reports whether path is an http or https URL rather than a
+// file path
+func isRemoteRegistry(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// fetchRegistry downloads the registry at url and parses it read-only. opts are
+// applied after registryOptions.
+func fetchRegistry(url string, opts ...registry.Option) (*registry.Registry, error) {
+	client := &http.Client{Timeout: remoteTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch registry %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registry: %w", err)
+	}
+	if len(data) > maxRemoteSize {
+		return nil, fmt.Errorf("failed to fetch registry %s: larger than %d bytes", url, maxRemoteSize)
+	}
+
+	return registry.NewFromData(url, data, append(registryOptions(), opts...)...)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/registry.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"assignments":[{"port":3100,"description":"web"}],"blockedPorts":[]}`))
+	}))
+	defer srv.Close()
+
+	reg, err := fetchRegistry(srv.URL + "/registry.json")
+	require.NoError(t, err)
+	a, ok := reg.GetAssignment(3100)
+	require.True(t, ok)
+	assert.Equal(t, "web", a.Description)
+
+	_, err = fetchRegistry(srv.URL + "/missing.json")
+	require.ErrorContains(t, err, "404")
+}
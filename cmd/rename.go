@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var renameByPath bool
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <old-description> <new-description>",
+	Short: "Change the description of all matching assignments",
+	Long: `Change the description of every assignment whose description is exactly
+<old-description>. With --by-path, the first argument is a project path instead
+and every assignment for that path is renamed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		var count int
+		if renameByPath {
+			count, err = reg.RenameByPath(args[0], args[1])
+		} else {
+			count, err = reg.Rename(args[0], args[1])
+		}
+		if err != nil {
+			return err
+		}
+
+		printInfo("Renamed %d assignment(s)\n", count)
+		return nil
+	},
+}
+
+func init() {
+	renameCmd.Flags().BoolVar(&renameByPath, "by-path", false, "Match assignments by project path instead of description")
+	rootCmd.AddCommand(renameCmd)
+}
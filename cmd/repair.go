@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Restore a corrupt registry file from its backup",
+	Long: `Restore an empty or unparsable registry file, such as one truncated by a crash,
+from the temporary file of an interrupted save or the backup kept by every save
+(<registry>.bak), whichever is the newest valid one. The corrupt file is kept as
+<registry>.corrupt.
+
+Project paths stored by older versions in relative or uncleaned form are then
+rewritten in absolute form, resolving relative paths against the current
+directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkWritable(); err != nil {
+			return err
+		}
+
+		result, err := registry.Repair(registryPath)
+		switch {
+		case errors.Is(err, registry.ErrNothingToRepair):
+			printInfo("Registry file is valid; nothing to repair\n")
+		case err != nil:
+			return err
+		default:
+			printInfo("Restored %s from %s\n", registryPath, result.Source)
+			if result.CorruptPath != "" {
+				printInfo("The corrupt file was kept at %s\n", result.CorruptPath)
+			}
+		}
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		canonicalized, err := reg.CanonicalizePaths()
+		if err != nil {
+			return err
+		}
+		if !quiet {
+			printCanonicalized(canonicalized, "Rewrote")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reserveDescription string
+	reserveForce       bool
+)
+
+var reserveCmd = &cobra.Command{
+	Use:   "reserve <port>",
+	Short: "Reserve a port for future use",
+	Long: `Reserve a port for future use without tying it to a project. Reserved ports
+are never auto-assigned. Use 'portreg unassign' to release a reservation.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid port number: %s", args[0])
+		}
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		reg.SetAllowAutoRange(reserveForce)
+
+		err = reg.Reserve(port, reserveDescription)
+		if err != nil {
+			if errors.Is(err, registry.ErrPortAlreadyAssigned) {
+				return fmt.Errorf("%w. Use 'portreg list' to see all assignments", err)
+			}
+			if errors.Is(err, registry.ErrPortInAutoRange) {
+				return fmt.Errorf("%w. Use --force to reserve it anyway", err)
+			}
+			return err
+		}
+
+		printInfo("Reserved port %d\n", port)
+		return nil
+	},
+}
+
+func init() {
+	reserveCmd.Flags().StringVarP(&reserveDescription, "description", "d", "", "Description of what the port is reserved for")
+	reserveCmd.Flags().BoolVar(&reserveForce, "force", false, "Reserve the port even if it is in a protected auto-assignment range")
+	rootCmd.AddCommand(reserveCmd)
+}
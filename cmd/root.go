@@ -1,30 +1,291 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 
+	"github.com/jackc/portreg/registry"
 	"github.com/spf13/cobra"
 )
 
-var registryPath string
+var (
+	registryPath string
+	readOnly     bool
+	verbose      bool
+	modeFlag     string
+	runHooks     bool
+	auditLog     string
+	quiet        bool
+	fileMode     os.FileMode
+	fileModeSet  bool
+
+	// registryDiscovered is set when the registry file was found by searching
+	// the current directory and its ancestors rather than chosen by the user
+	registryDiscovered bool
+
+	// logger receives the registry's debug logs when --verbose is set
+	logger *slog.Logger
+)
+
+// registryFileName is the name of the registry file looked for in the current
+// directory and its ancestors
+const registryFileName = ".portreg.json"
+
+// registryEnvVar names the environment variable that overrides the default
+// registry path
+const registryEnvVar = "PORTREG_REGISTRY"
+
+// xdgRegistryFile is the path of the default registry file relative to the
+// XDG config directory
+var xdgRegistryFile = filepath.Join("portreg", "registry.json")
+
+// modeEnvVar names the environment variable that sets the registry file mode
+const modeEnvVar = "PORTREG_MODE"
+
+// runHooksEnvVar names the environment variable that opts in to running the
+// registry's onChange hook like --run-hooks
+const runHooksEnvVar = "PORTREG_RUN_HOOKS"
 
 var rootCmd = &cobra.Command{
 	Use:   "portreg",
 	Short: "A port registry tool to manage port assignments",
 	Long: `portreg helps developers manage port assignments across multiple projects
 to avoid conflicts. It uses static port assignment stored in a JSON registry file.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		wd, _ := os.Getwd()
+		registryPath, registryDiscovered = resolveRegistryPath(registryPath, cmd.Flags().Changed("registry"), os.Getenv(registryEnvVar), wd)
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Using registry %s\n", registryPath)
+			logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		}
+
+		mode := os.Getenv(modeEnvVar)
+		if cmd.Flags().Changed("mode") {
+			mode = modeFlag
+		}
+		if mode != "" {
+			m, err := parseFileMode(mode)
+			if err != nil {
+				return err
+			}
+			fileMode = m
+			fileModeSet = true
+		}
+
+		if !cmd.Flags().Changed("run-hooks") {
+			if v := os.Getenv(runHooksEnvVar); v != "" {
+				run, err := strconv.ParseBool(v)
+				if err != nil {
+					return fmt.Errorf("invalid %s %q: %w", runHooksEnvVar, v, err)
+				}
+				runHooks = run
+			}
+		}
+		return nil
+	},
 }
 
+// Exit codes returned by portreg. Any error without a specific code exits with exitError.
+const (
+	exitError           = 1
+	exitAlreadyAssigned = 2
+	exitBlocked         = 3
+	exitNotAssigned     = 4
+	exitNoPorts         = 5
+)
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if errors.Is(err, registry.ErrCorruptRegistry) {
+			fmt.Fprintln(os.Stderr, "Run 'portreg repair' to restore the registry from its backup")
+		} else if errors.Is(err, registry.ErrMalformedRegistry) {
+			fmt.Fprintln(os.Stderr, "Run 'portreg gc' to remove the malformed entries")
+		}
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode maps an error to the process exit code
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, registry.ErrPortAlreadyAssigned):
+		return exitAlreadyAssigned
+	case errors.Is(err, registry.ErrPortBlocked):
+		return exitBlocked
+	case errors.Is(err, registry.ErrPortNotAssigned):
+		return exitNotAssigned
+	case errors.Is(err, registry.ErrNoPortsAvailable):
+		return exitNoPorts
+	default:
+		return exitError
+	}
+}
+
+// assignmentLookupError adds a hint to the errors of looking up an assignment
+// by port: how to pick a protocol, or where to find the assigned ports
+func assignmentLookupError(err error) error {
+	switch {
+	case errors.Is(err, registry.ErrAmbiguousProtocol):
+		return fmt.Errorf("%w. Use --protocol tcp or --protocol udp to choose one", err)
+	case errors.Is(err, registry.ErrPortNotAssigned):
+		return fmt.Errorf("%w. Use 'portreg list' to see all assignments", err)
+	}
+	return err
+}
+
+// printInfo prints a confirmation or other informational message to stdout
+// unless --quiet is set. Errors, warnings, and the data a command was asked
+// for are printed directly instead.
+func printInfo(format string, a ...any) {
+	if quiet {
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+// registryOptions returns the options every command loads the registry with
+func registryOptions() []registry.Option {
+	var opts []registry.Option
+	if logger != nil {
+		opts = append(opts, registry.WithLogger(logger))
+	}
+	if auditLog != "" {
+		opts = append(opts, registry.WithAuditLog(auditLog))
+	}
+	return opts
+}
+
+// loadRegistry loads the registry for a command that does not modify it. A
+// registry given as an http or https URL is fetched instead of read from disk.
+// opts are applied after registryOptions.
+func loadRegistry(opts ...registry.Option) (*registry.Registry, error) {
+	if isRemoteRegistry(registryPath) {
+		return fetchRegistry(registryPath, opts...)
+	}
+
+	reg, err := registry.New(registryPath, append(registryOptions(), opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry: %w", err)
+	}
+	return reg, nil
+}
+
+// openLockedRegistry loads the registry and locks it for a read-modify-write
+// cycle. The caller must release the lock with Unlock. opts are applied after
+// registryOptions.
+func openLockedRegistry(opts ...registry.Option) (*registry.Registry, error) {
+	if err := checkWritable(); err != nil {
+		return nil, err
+	}
+
+	reg, err := registry.New(registryPath, append(registryOptions(), opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry: %w", err)
+	}
+
+	if err := reg.Lock(); err != nil {
+		return nil, err
+	}
+	applyFileMode(reg)
+
+	return reg, nil
+}
+
+// applyFileMode sets the registry file mode from --mode or PORTREG_MODE. When
+// neither is given the registry keeps its default so the umask applies.
+func applyFileMode(reg *registry.Registry) {
+	if fileModeSet {
+		reg.SetFileMode(fileMode)
+	}
+}
+
+// parseFileMode parses an octal permission mode such as 0600 or 600
+func parseFileMode(s string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || m > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q (must be octal permissions such as 0600)", s)
+	}
+	return os.FileMode(m), nil
+}
+
+// resolveRegistryPath picks the registry file to use. An explicit --registry
+// flag wins, then the PORTREG_REGISTRY environment variable, then the nearest
+// registry file discovered from wd, and finally the flag default. discovered
+// reports whether the discovered file was picked.
+func resolveRegistryPath(flagPath string, flagSet bool, envPath string, wd string) (path string, discovered bool) {
+	if flagSet {
+		return flagPath, false
+	}
+	if envPath != "" {
+		return envPath, false
+	}
+	if wd != "" {
+		if path, ok := discoverRegistry(wd); ok {
+			// The legacy default registry in $HOME is found by discovery too
+			return path, path != flagPath
+		}
+	}
+	return flagPath, false
+}
+
+// defaultRegistryPath returns the registry file used when none is given or
+// discovered: $HOME/.portreg.json if that legacy file exists, otherwise
+// portreg/registry.json in $XDG_CONFIG_HOME, or in $HOME/.config if
+// XDG_CONFIG_HOME is not set to an absolute path.
+func defaultRegistryPath(home, xdgConfigHome string) string {
+	legacy := filepath.Join(home, registryFileName)
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy
+	}
+
+	if !filepath.IsAbs(xdgConfigHome) {
+		xdgConfigHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(xdgConfigHome, xdgRegistryFile)
+}
+
+// discoverRegistry looks for a registry file in dir and each of its ancestors,
+// like git does for .git. It returns the path of the nearest one found.
+func discoverRegistry(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, registryFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// checkWritable fails when --read-only is set or the registry is a URL.
+// Mutating commands call it before doing any work.
+func checkWritable() error {
+	if isRemoteRegistry(registryPath) {
+		return fmt.Errorf("%w: %s is a remote registry; only commands that do not change the registry can use it", registry.ErrReadOnly, registryPath)
+	}
+	if readOnly {
+		return fmt.Errorf("%w: refusing to modify %s", registry.ErrReadOnly, registryPath)
 	}
+	return nil
 }
 
 func init() {
-	defaultPath := filepath.Join(os.Getenv("HOME"), ".portreg.json")
+	defaultPath := defaultRegistryPath(os.Getenv("HOME"), os.Getenv("XDG_CONFIG_HOME"))
 	rootCmd.PersistentFlags().StringVarP(&registryPath, "registry", "r", defaultPath, "Path to registry file")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Do not print confirmations such as \"Unassigned port 3100\"; errors and requested data are still printed")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print the registry file in use and debug logs of registry operations to stderr")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Never modify the registry file; mutating commands fail")
+	rootCmd.PersistentFlags().StringVar(&modeFlag, "mode", "", "Permission mode to write the registry file with, in octal (default 0644 minus the umask)")
+	rootCmd.PersistentFlags().BoolVar(&runHooks, "run-hooks", false, "Run the registry's onChange hook (never run for a discovered .portreg.json)")
+	rootCmd.PersistentFlags().StringVar(&auditLog, "audit-log", "", "Append changes to this audit log file (default <registry>.log when the registry sets audit)")
 }
\ No newline at end of file
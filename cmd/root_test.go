@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRegistryPath(t *testing.T) {
+	dir := t.TempDir()
+	projectRegistry := filepath.Join(dir, registryFileName)
+	require.NoError(t, os.WriteFile(projectRegistry, []byte(`{"assignments":[],"blockedPorts":[]}`), 0644))
+	wd := filepath.Join(dir, "sub", "dir")
+	require.NoError(t, os.MkdirAll(wd, 0755))
+
+	defaultPath := "/home/user/.portreg.json"
+
+	t.Run("flag wins over everything", func(t *testing.T) {
+		path, discovered := resolveRegistryPath("/flag.json", true, "/env.json", wd)
+		assert.Equal(t, "/flag.json", path)
+		assert.False(t, discovered)
+	})
+
+	t.Run("environment wins over discovery and default", func(t *testing.T) {
+		path, discovered := resolveRegistryPath(defaultPath, false, "/env.json", wd)
+		assert.Equal(t, "/env.json", path)
+		assert.False(t, discovered)
+	})
+
+	t.Run("discovered registry wins over default", func(t *testing.T) {
+		path, discovered := resolveRegistryPath(defaultPath, false, "", wd)
+		assert.Equal(t, projectRegistry, path)
+		assert.True(t, discovered)
+	})
+
+	t.Run("falls back to default", func(t *testing.T) {
+		path, discovered := resolveRegistryPath(defaultPath, false, "", t.TempDir())
+		assert.Equal(t, defaultPath, path)
+		assert.False(t, discovered)
+	})
+
+	t.Run("legacy default found by discovery is not discovered", func(t *testing.T) {
+		path, discovered := resolveRegistryPath(projectRegistry, false, "", wd)
+		assert.Equal(t, projectRegistry, path)
+		assert.False(t, discovered)
+	})
+}
+
+func TestDefaultRegistryPath(t *testing.T) {
+	home := t.TempDir()
+
+	assert.Equal(t, "/xdg/portreg/registry.json", defaultRegistryPath(home, "/xdg"))
+	assert.Equal(t, filepath.Join(home, ".config", "portreg", "registry.json"), defaultRegistryPath(home, ""))
+	assert.Equal(t, filepath.Join(home, ".config", "portreg", "registry.json"), defaultRegistryPath(home, "relative"))
+
+	legacy := filepath.Join(home, registryFileName)
+	require.NoError(t, os.WriteFile(legacy, []byte(`{"assignments":[],"blockedPorts":[]}`), 0644))
+	assert.Equal(t, legacy, defaultRegistryPath(home, "/xdg"))
+}
+
+func TestParseFileMode(t *testing.T) {
+	mode, err := parseFileMode("0600")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), mode)
+
+	mode, err = parseFileMode("640")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), mode)
+
+	for _, s := range []string{"", "rw", "0800", "1777"} {
+		_, err := parseFileMode(s)
+		assert.Error(t, err, s)
+	}
+}
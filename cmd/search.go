@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var searchFormat string
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search assigned ports by description or path",
+	Long: `Display assigned ports whose description or path contains the query
+(case-insensitive) in a table or JSON format.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		assignments := reg.Search(args[0])
+
+		if searchFormat == "json" {
+			// JSON output
+			return printJSON(assignments)
+		}
+
+		// Table output
+		if len(assignments) == 0 {
+			fmt.Printf("No matches for '%s'\n", args[0])
+			return nil
+		}
+
+		printAssignmentTable(assignments, false)
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchFormat, "format", "table", "Output format (table or json)")
+	rootCmd.AddCommand(searchCmd)
+}
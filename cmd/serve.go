@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveHost string
+	servePort int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the registry read-only over HTTP",
+	Long: `Start a read-only HTTP server exposing the registry as JSON:
+
+  GET /assignments         all assignments
+  GET /assignments/{port}  a single assignment (404 if not assigned)
+  GET /blocked             all blocked entries
+
+The registry file is read again for every request, so responses always reflect
+the current file. There are no endpoints that change the registry. The server
+listens on 127.0.0.1 unless --host is given; it has no authentication, so only
+listen on other addresses on trusted networks.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Fail at startup rather than on the first request
+		if _, err := loadServedRegistry(registryPath); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		srv := &http.Server{
+			Addr:              net.JoinHostPort(serveHost, strconv.Itoa(servePort)),
+			Handler:           newServeHandler(registryPath),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- srv.ListenAndServe()
+		}()
+		printInfo("Serving %s at http://%s\n", registryPath, srv.Addr)
+
+		select {
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// newServeHandler returns the read-only HTTP API for the registry at path
+func newServeHandler(path string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /assignments", func(w http.ResponseWriter, r *http.Request) {
+		reg, err := loadServedRegistry(path)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, withProtocols(reg.ListAssignments()))
+	})
+
+	mux.HandleFunc("GET /assignments/{port}", func(w http.ResponseWriter, r *http.Request) {
+		port, err := strconv.Atoi(r.PathValue("port"))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid port number: %s", r.PathValue("port")))
+			return
+		}
+
+		reg, err := loadServedRegistry(path)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		a, ok := reg.GetAssignment(port)
+		if !ok {
+			writeJSONError(w, http.StatusNotFound, fmt.Errorf("%w: port %d", registry.ErrPortNotAssigned, port))
+			return
+		}
+		writeJSON(w, http.StatusOK, withProtocols([]registry.Assignment{a})[0])
+	})
+
+	mux.HandleFunc("GET /blocked", func(w http.ResponseWriter, r *http.Request) {
+		reg, err := loadServedRegistry(path)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, reg.ListBlockedPorts())
+	})
+
+	return mux
+}
+
+// loadServedRegistry loads the registry for a request. It is read-only so the
+// server can never modify the file. A remote registry is fetched again.
+func loadServedRegistry(path string) (*registry.Registry, error) {
+	if isRemoteRegistry(path) {
+		return fetchRegistry(path)
+	}
+
+	reg, err := registry.New(path, append(registryOptions(), registry.WithReadOnly(true))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry: %w", err)
+	}
+	return reg, nil
+}
+
+// writeJSON writes v as the JSON response body with status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError writes err as a JSON object {"error": "..."} with status
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveHost, "host", "127.0.0.1", "Address to listen on")
+	serveCmd.Flags().IntVarP(&servePort, "port", "p", 7070, "Port to listen on")
+	rootCmd.AddCommand(serveCmd)
+}
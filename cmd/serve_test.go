@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[{"port":3100,"description":"web"}],"blockedPorts":[{"ports":"5432","description":"postgres"}]}`), 0644))
+	handler := newServeHandler(path)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	rec := get("/assignments")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"port":3100,"description":"web","protocol":"tcp"}]`, rec.Body.String())
+
+	rec = get("/assignments/3100")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"port":3100,"description":"web","protocol":"tcp"}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, get("/assignments/3101").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/assignments/web").Code)
+
+	rec = get("/blocked")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"ports":"5432","description":"postgres"}]`, rec.Body.String())
+
+	// The file is read on every request
+	require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[],"blockedPorts":[]}`), 0644))
+	assert.JSONEq(t, `[]`, get("/assignments").Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/assignments/3100", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	showFormat   string
+	showProtocol string
+)
+
+var showCmd = &cobra.Command{
+	Use:               "show <port>",
+	Short:             "Display a single port assignment",
+	Long:              `Display the details of a single port assignment in a table or JSON format.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAssignedPort,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid port number: %s", args[0])
+		}
+
+		var a registry.Assignment
+		var ok bool
+		if isLargeRegistry(registryPath) {
+			// Scan large files instead of loading them; misses fall through to
+			// a full load so blocked ports are still reported
+			a, ok, err = registry.LookupStreamingProtocol(registryPath, port, showProtocol)
+			if errors.Is(err, registry.ErrAmbiguousProtocol) {
+				return assignmentLookupError(err)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load registry: %w", err)
+			}
+		}
+
+		if !ok {
+			reg, err := loadRegistry()
+			if err != nil {
+				return err
+			}
+
+			status := reg.PortStatus(port)
+			switch status.State {
+			case registry.PortAssigned:
+				if status.Source != "" {
+					return fmt.Errorf("%w: port %d is assigned to '%s' in included file %s", registry.ErrPortNotAssigned, port, status.Assignment.Description, status.Source)
+				}
+				a, err = reg.LookupAssignment(port, showProtocol)
+				if err != nil {
+					return assignmentLookupError(err)
+				}
+			case registry.PortBlocked:
+				return fmt.Errorf("%w: port %d is blocked by '%s' (%s)", registry.ErrPortNotAssigned, port, status.BlockedPort.Ports, status.BlockedPort.Description)
+			case registry.PortNotAllowed:
+				return fmt.Errorf("%w: port %d is outside the allowed ranges", registry.ErrPortNotAssigned, port)
+			default:
+				return fmt.Errorf("%w: port %d. Use 'portreg list' to see all assignments", registry.ErrPortNotAssigned, port)
+			}
+		}
+
+		if showFormat == "json" {
+			// JSON output
+			if err := printJSON(withProtocols([]registry.Assignment{a})[0]); err != nil {
+				return err
+			}
+		} else {
+			// Table output
+			path := a.Path
+			if path == "" {
+				path = "-"
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Port:\t%d\n", a.Port)
+			fmt.Fprintf(w, "Protocol:\t%s\n", a.EffectiveProtocol())
+			if a.Name != "" {
+				fmt.Fprintf(w, "Name:\t%s\n", a.Name)
+			}
+			fmt.Fprintf(w, "Description:\t%s\n", a.Description)
+			fmt.Fprintf(w, "Path:\t%s\n", path)
+			if a.Reserved {
+				fmt.Fprintf(w, "Status:\treserved\n")
+			}
+			if len(a.Tags) > 0 {
+				fmt.Fprintf(w, "Tags:\t%s\n", strings.Join(a.Tags, ", "))
+			}
+			if a.Notes != "" {
+				fmt.Fprintf(w, "Notes:\t%s\n", a.Notes)
+			}
+			if a.Label != "" {
+				fmt.Fprintf(w, "Label:\t%s\n", a.Label)
+			}
+			if !a.CreatedAt.IsZero() {
+				fmt.Fprintf(w, "Created:\t%s\n", a.CreatedAt.Local().Format(time.RFC3339))
+			}
+			if !a.ExpiresAt.IsZero() {
+				expires := a.ExpiresAt.Local().Format(time.RFC3339)
+				if a.Expired(time.Now()) {
+					expires += " (expired)"
+				}
+				fmt.Fprintf(w, "Expires:\t%s\n", expires)
+			}
+			w.Flush()
+		}
+
+		return nil
+	},
+}
+
+// isLargeRegistry reports whether path is a JSON registry file larger than
+// registry.StreamingThreshold
+func isLargeRegistry(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > registry.StreamingThreshold
+}
+
+func init() {
+	showCmd.Flags().StringVar(&showFormat, "format", "table", "Output format (table or json)")
+	showCmd.Flags().StringVar(&showProtocol, "protocol", "", "Protocol of the assignment to show (tcp or udp); required if the port is assigned for both")
+	rootCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var statsFormat string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Display a summary of the registry",
+	Long: `Display how many ports are assigned and blocked, the range of assigned ports,
+how many ports remain available for auto-assignment, and the largest block of
+consecutive available ports.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		stats := reg.Stats()
+
+		if statsFormat == "json" {
+			return printJSON(stats)
+		}
+
+		usedRange := "-"
+		if stats.AssignmentCount > 0 {
+			usedRange = fmt.Sprintf("%d-%d", stats.LowestPort, stats.HighestPort)
+		}
+
+		largestBlock := "-"
+		if stats.LargestFreeBlockLength > 0 {
+			end := stats.LargestFreeBlockStart + stats.LargestFreeBlockLength - 1
+			largestBlock = fmt.Sprintf("%d (%d-%d)", stats.LargestFreeBlockLength, stats.LargestFreeBlockStart, end)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Assigned:\t%d\n", stats.AssignmentCount)
+		fmt.Fprintf(w, "Blocked ranges:\t%d\n", stats.BlockedRangeCount)
+		fmt.Fprintf(w, "Port range used:\t%s\n", usedRange)
+		fmt.Fprintf(w, "Available (%d-%d):\t%d\n", reg.StartPort(), reg.EndPort(), stats.AvailableCount)
+		fmt.Fprintf(w, "Largest free block:\t%s\n", largestBlock)
+		return w.Flush()
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsFormat, "format", "table", "Output format (table or json)")
+	rootCmd.AddCommand(statsCmd)
+}
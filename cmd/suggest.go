@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestStart  int
+	suggestEnd    int
+	suggestFormat string
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Print a memorable available port without assigning it",
+	Long: `Print an available port that is easy to remember, preferring multiples of
+100 and then of 10, without assigning it. Ports commonly used by development
+tools, such as 3000 and 8000, are skipped. If no round port is available the
+next available port is printed, like 'portreg next'.
+
+Assign the suggestion with 'portreg assign -p <port>'. Suggestions come from
+the auto-assignment range, so add --force if the registry sets
+protectAutoRange.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		if suggestStart > 0 {
+			reg.SetStartPort(suggestStart)
+		}
+		if suggestEnd > 0 {
+			reg.SetEndPort(suggestEnd)
+		}
+
+		port, err := reg.Suggest()
+		if err != nil {
+			return err
+		}
+
+		if suggestFormat == "json" {
+			return printCompactJSON(struct {
+				Port int `json:"port"`
+			}{port})
+		}
+
+		fmt.Println(port)
+		return nil
+	},
+}
+
+func init() {
+	suggestCmd.Flags().IntVar(&suggestStart, "start", 0, "Port to start from (overrides registry start port)")
+	suggestCmd.Flags().IntVar(&suggestEnd, "end", 0, "Last port to consider (overrides registry end port)")
+	suggestCmd.Flags().StringVar(&suggestFormat, "format", "text", "Output format (text or json)")
+	rootCmd.AddCommand(suggestCmd)
+}
@@ -1,43 +1,178 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
+	"os"
 	"strconv"
 
 	"github.com/jackc/portreg/registry"
 	"github.com/spf13/cobra"
 )
 
+var (
+	unassignYes         bool
+	unassignPath        string
+	unassignDescription string
+	unassignProtocol    string
+)
+
 var unassignCmd = &cobra.Command{
 	Use:   "unassign <port>",
 	Short: "Release a port assignment",
-	Long:  `Release a port assignment by port number.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Release a port assignment by port number, or every assignment for a project
+with --path or --description. The assignments are shown and confirmation is
+asked for unless --yes is given. When stdin is not a terminal --yes is
+required.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if unassignPath != "" || unassignDescription != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("a port cannot be given with --path or --description")
+			}
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	ValidArgsFunction: completeAssignedPort,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// Fail before prompting; the preview below would otherwise load a
+		// remote or read-only registry only to have the change refused.
+		if err := checkWritable(); err != nil {
+			return err
+		}
+
+		if unassignPath != "" || unassignDescription != "" {
+			return unassignBulk()
+		}
+
 		port, err := strconv.Atoi(args[0])
 		if err != nil {
 			return fmt.Errorf("invalid port number: %s", args[0])
 		}
 
-		reg, err := registry.New(registryPath)
-		if err != nil {
-			return fmt.Errorf("failed to load registry: %w", err)
+		if !unassignYes {
+			ok, err := confirmUnassign(port)
+			if err != nil || !ok {
+				return err
+			}
 		}
 
-		err = reg.UnassignPort(port)
+		reg, err := openLockedRegistry()
 		if err != nil {
-			if errors.Is(err, registry.ErrPortNotAssigned) {
-				return fmt.Errorf("%w. Use 'portreg list' to see all assignments", err)
-			}
 			return err
 		}
+		defer reg.Unlock()
+
+		err = reg.UnassignProtocol(port, unassignProtocol)
+		if err != nil {
+			return assignmentLookupError(err)
+		}
 
-		fmt.Printf("Unassigned port %d\n", port)
+		printInfo("Unassigned port %d\n", port)
+		runChangeHook(reg, hookUnassign, port)
 		return nil
 	},
 }
 
+// unassignBulk releases every assignment matching --path or --description
+func unassignBulk() error {
+	if !unassignYes {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("refusing to unassign without confirmation. Use --yes when not running interactively")
+		}
+
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		var matches []registry.Assignment
+		if unassignPath != "" {
+			matches = reg.FindByPath(unassignPath)
+		} else {
+			matches = reg.FindByDescription(unassignDescription)
+		}
+		if len(matches) == 0 {
+			printInfo("Unassigned 0 port(s)\n")
+			return nil
+		}
+
+		printAssignmentTable(matches, false)
+		ok, err := confirm(fmt.Sprintf("Unassign these %d port(s)?", len(matches)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	reg, err := openLockedRegistry()
+	if err != nil {
+		return err
+	}
+	defer reg.Unlock()
+
+	var matches []registry.Assignment
+	var count int
+	if unassignPath != "" {
+		matches = reg.FindByPath(unassignPath)
+		count, err = reg.UnassignByPath(unassignPath)
+	} else {
+		matches = reg.FindByDescription(unassignDescription)
+		count, err = reg.UnassignByDescription(unassignDescription)
+	}
+	if err != nil {
+		return err
+	}
+
+	printInfo("Unassigned %d port(s)\n", count)
+	ports := make([]int, len(matches))
+	for i, a := range matches {
+		ports[i] = a.Port
+	}
+	runChangeHook(reg, hookUnassign, ports...)
+	return nil
+}
+
+// confirmUnassign shows the assignment of port and asks whether to unassign it.
+// It fails instead of prompting when stdin is not a terminal.
+func confirmUnassign(port int) (bool, error) {
+	if !isTerminal(os.Stdin) {
+		return false, fmt.Errorf("refusing to unassign port %d without confirmation. Use --yes when not running interactively", port)
+	}
+
+	reg, err := loadRegistry()
+	if err != nil {
+		return false, err
+	}
+
+	a, err := reg.LookupAssignment(port, unassignProtocol)
+	if err != nil {
+		return false, assignmentLookupError(err)
+	}
+
+	path := a.Path
+	if path == "" {
+		path = "-"
+	}
+	ok, err := confirm(fmt.Sprintf("Unassign port %d/%s (%s, path: %s)?", a.Port, a.EffectiveProtocol(), displayDescription(a), path))
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		fmt.Println("Aborted")
+	}
+	return ok, nil
+}
+
 func init() {
+	unassignCmd.Flags().BoolVar(&unassignYes, "yes", false, "Unassign without asking for confirmation")
+	unassignCmd.Flags().StringVar(&unassignPath, "path", "", "Unassign every port assigned to this project path")
+	unassignCmd.Flags().StringVarP(&unassignDescription, "description", "d", "", "Unassign every port with exactly this description")
+	unassignCmd.Flags().StringVar(&unassignProtocol, "protocol", "", "Protocol of the assignment to release (tcp or udp); required if the port is assigned for both")
+	unassignCmd.MarkFlagsMutuallyExclusive("path", "description")
+	unassignCmd.MarkFlagsMutuallyExclusive("path", "protocol")
+	unassignCmd.MarkFlagsMutuallyExclusive("description", "protocol")
 	rootCmd.AddCommand(unassignCmd)
 }
\ No newline at end of file
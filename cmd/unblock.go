@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var unblockCmd = &cobra.Command{
+	Use:   "unblock <port|range>",
+	Short: "Remove a blocked port or range",
+	Long: `Remove a blocked port or range of ports. The argument must exactly match the
+blocked entry (e.g. 4000-4010).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec := args[0]
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		if err := reg.UnblockPorts(spec); err != nil {
+			return err
+		}
+
+		printInfo("Unblocked %s\n", spec)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unblockCmd)
+}
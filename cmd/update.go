@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updatePath        string
+	updateDescription string
+	updateNotes       string
+	updateLabel       string
+	updateProtocol    string
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update <port>",
+	Short: "Update a port assignment",
+	Long: `Update the description, path, notes, or label of an existing port assignment.
+Values that are not specified are left unchanged.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid port number: %s", args[0])
+		}
+
+		reg, err := openLockedRegistry()
+		if err != nil {
+			return err
+		}
+		defer reg.Unlock()
+
+		a, err := reg.LookupAssignment(port, updateProtocol)
+		if err != nil {
+			return assignmentLookupError(err)
+		}
+
+		if cmd.Flags().Changed("description") {
+			a.Description = updateDescription
+		}
+		if cmd.Flags().Changed("path") {
+			a.Path = updatePath
+		}
+		if cmd.Flags().Changed("notes") {
+			a.Notes = updateNotes
+		}
+		if cmd.Flags().Changed("label") {
+			a.Label = updateLabel
+		}
+
+		err = reg.Update(a)
+		if err != nil {
+			if errors.Is(err, registry.ErrPortNotAssigned) {
+				return fmt.Errorf("%w. Use 'portreg list' to see all assignments", err)
+			}
+			return err
+		}
+
+		printInfo("Updated port %d\n", port)
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringVar(&updatePath, "path", "", "Project path")
+	updateCmd.Flags().StringVarP(&updateDescription, "description", "d", "", "Description for the port assignment")
+	updateCmd.Flags().StringVar(&updateNotes, "notes", "", "Longer freeform notes for the port assignment")
+	updateCmd.Flags().StringVar(&updateLabel, "label", "", "Color label to highlight the port with in list; empty removes it")
+	updateCmd.Flags().StringVar(&updateProtocol, "protocol", "", "Protocol of the assignment to update (tcp or udp); required if the port is assigned for both")
+	rootCmd.AddCommand(updateCmd)
+}
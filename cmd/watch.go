@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchFormat   string
+)
+
+// watchEvent is a change to the registry printed by watch --format json
+type watchEvent struct {
+	Time    time.Time                   `json:"time"`
+	Added   []registry.Assignment       `json:"added"`
+	Removed []registry.Assignment       `json:"removed"`
+	Changed []registry.AssignmentChange `json:"changed"`
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print changes to the registry file as they happen",
+	Long: `Watch the registry file and print the assignments added, removed, or changed
+each time it is modified, until interrupted. The file is checked every
+--interval by its modification time and size.
+
+Each change is printed on its own line, prefixed with the time it was seen and
++ (added), - (removed), or ~ (changed). With --format json each modification is
+printed as one JSON object per line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if watchInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		if watchFormat != "text" && watchFormat != "json" {
+			return fmt.Errorf("invalid format: %s (must be text or json)", watchFormat)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		printInfo("Watching %s (interrupt to stop)\n", registryPath)
+		return watchRegistry(ctx, registryPath, watchInterval, printWatchEvent)
+	},
+}
+
+// watchRegistry polls the registry file at path every interval and calls
+// onChange with the difference from the previous contents (A) to the new
+// contents (B) each time the file's modification time or size changes. A
+// remote registry is fetched on every tick instead. Files that fail to load
+// are reported on stderr once and retried on every tick, so a change is not
+// lost when the file is read in the middle of a write. It returns when ctx is
+// done.
+func watchRegistry(ctx context.Context, path string, interval time.Duration, onChange func(time.Time, registry.DiffResult) error) error {
+	remote := isRemoteRegistry(path)
+	load := func() (*registry.Registry, error) {
+		if remote {
+			return fetchRegistry(path)
+		}
+		return registry.New(path, append(registryOptions(), registry.WithReadOnly(true))...)
+	}
+
+	prev, err := load()
+	if err != nil {
+		return fmt.Errorf("failed to load registry: %w", err)
+	}
+	prevInfo, _ := os.Stat(path)
+
+	// failedInfo is the file state that last failed to load
+	var failedInfo os.FileInfo
+	failed := false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		var info os.FileInfo
+		if !remote {
+			info, _ = os.Stat(path)
+			if sameFileState(prevInfo, info) {
+				continue
+			}
+		}
+
+		cur, err := load()
+		if err != nil {
+			if !failed || !sameFileState(failedInfo, info) {
+				fmt.Fprintf(os.Stderr, "Warning: failed to load registry: %v\n", err)
+			}
+			failedInfo, failed = info, true
+			continue
+		}
+		prevInfo, failed = info, false
+
+		if diff := prev.Diff(cur); !diff.Empty() {
+			if err := onChange(time.Now(), diff); err != nil {
+				return err
+			}
+		}
+		prev = cur
+	}
+}
+
+// sameFileState reports whether two stats of a file show the same modification
+// time and size. A nil info means the file did not exist.
+func sameFileState(a, b os.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// printWatchEvent prints the changes of a registry modification in the
+// --format of the watch command
+func printWatchEvent(t time.Time, diff registry.DiffResult) error {
+	if watchFormat == "json" {
+		return printCompactJSON(watchEvent{
+			Time:    t.UTC().Truncate(time.Second),
+			Added:   withProtocols(diff.OnlyInB),
+			Removed: withProtocols(diff.OnlyInA),
+			Changed: diff.Changed,
+		})
+	}
+
+	ts := t.Format(time.TimeOnly)
+	for _, a := range diff.OnlyInB {
+		fmt.Printf("%s + %d %s %s\n", ts, a.Port, orDash(a.Description), orDash(a.Path))
+	}
+	for _, a := range diff.OnlyInA {
+		fmt.Printf("%s - %d %s %s\n", ts, a.Port, orDash(a.Description), orDash(a.Path))
+	}
+	for _, c := range diff.Changed {
+		if c.A.Description != c.B.Description {
+			fmt.Printf("%s ~ %d description: %s -> %s\n", ts, c.A.Port, orDash(c.A.Description), orDash(c.B.Description))
+		}
+		if c.A.Path != c.B.Path {
+			fmt.Printf("%s ~ %d path: %s -> %s\n", ts, c.A.Port, orDash(c.A.Path), orDash(c.B.Path))
+		}
+	}
+	return nil
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Second, "How often to check the registry file for changes")
+	watchCmd.Flags().StringVar(&watchFormat, "format", "text", "Output format (text or json)")
+	rootCmd.AddCommand(watchCmd)
+}
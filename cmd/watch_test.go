@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/portreg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[{"port":3100,"description":"web"},{"port":3101,"description":"api"}],"blockedPorts":[]}`), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	diffs := make(chan registry.DiffResult, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- watchRegistry(ctx, path, 10*time.Millisecond, func(_ time.Time, diff registry.DiffResult) error {
+			diffs <- diff
+			return nil
+		})
+	}()
+
+	// Let the watcher load the initial contents before rewriting the file
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[{"port":3100,"description":"frontend"},{"port":3102,"description":"db"}],"blockedPorts":[]}`), 0644))
+
+	select {
+	case diff := <-diffs:
+		assert.Equal(t, []int{3101}, assignmentPorts(diff.OnlyInA))
+		assert.Equal(t, []int{3102}, assignmentPorts(diff.OnlyInB))
+		require.Len(t, diff.Changed, 1)
+		assert.Equal(t, "web", diff.Changed[0].A.Description)
+		assert.Equal(t, "frontend", diff.Changed[0].B.Description)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatchRegistryRetriesFailedLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[{"port":3100,"description":"web"}],"blockedPorts":[]}`), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	diffs := make(chan registry.DiffResult, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- watchRegistry(ctx, path, 10*time.Millisecond, func(_ time.Time, diff registry.DiffResult) error {
+			diffs <- diff
+			return nil
+		})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// A partly written file and the finished file with the same modification
+	// time and size, as when a write is seen halfway on a coarse clock
+	mtime := time.Now().Add(time.Hour).Truncate(time.Second)
+	valid := `{"assignments":[{"port":3101,"description":"api"}],"blockedPorts":[]}`
+	require.NoError(t, os.WriteFile(path, []byte(valid[:len(valid)-1]+" "), 0644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte(valid), 0644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+
+	select {
+	case diff := <-diffs:
+		assert.Equal(t, []int{3100}, assignmentPorts(diff.OnlyInA))
+		assert.Equal(t, []int{3101}, assignmentPorts(diff.OnlyInB))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestSameFileState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	a, err := os.Stat(path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[]}`), 0644))
+	b, err := os.Stat(path)
+	require.NoError(t, err)
+
+	assert.True(t, sameFileState(a, a))
+	assert.True(t, sameFileState(nil, nil))
+	assert.False(t, sameFileState(a, b))
+	assert.False(t, sameFileState(a, nil))
+	assert.False(t, sameFileState(nil, b))
+}
+
+// assignmentPorts returns the ports of assignments in order
+func assignmentPorts(assignments []registry.Assignment) []int {
+	ports := []int{}
+	for _, a := range assignments {
+		ports = append(ports, a.Port)
+	}
+	return ports
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var whoamiPath string
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Display the ports assigned to a project path",
+	Long: `Display the ports assigned to a project path. The path defaults to the
+current directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := loadRegistry()
+		if err != nil {
+			return err
+		}
+
+		// Use current directory if no path specified
+		path := whoamiPath
+		if path == "" {
+			path, err = os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get current directory: %w", err)
+			}
+		}
+
+		assignments := reg.FindByPath(path)
+		if len(assignments) == 0 {
+			fmt.Printf("No ports assigned to %s\n", path)
+			return nil
+		}
+
+		for _, a := range assignments {
+			fmt.Println(a.Port)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	whoamiCmd.Flags().StringVar(&whoamiPath, "path", "", "Project path (defaults to current directory)")
+	rootCmd.AddCommand(whoamiCmd)
+}
@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrPortNotAllowed is returned when a port outside every allowed range is
+// assigned while the registry has allowed ports
+var ErrPortNotAllowed = errors.New("port is not in an allowed range")
+
+// AllowedPort is a port or range of ports that may be assigned. When a
+// registry has any allowed ports, ports outside all of them are never assigned.
+type AllowedPort struct {
+	Ports       string `json:"ports" yaml:"ports"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// AllowPorts adds a port, range of ports, or pattern to the allowed ports. It
+// fails with ErrPortBlocked if every port it covers is blocked. It returns the
+// existing assignments that are outside every allowed range afterwards.
+func (r *Registry) AllowPorts(spec, description string) ([]Assignment, error) {
+	ranges, err := validateBlockedSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.rangesFullyBlocked(ranges) {
+		return nil, fmt.Errorf("%w: every port in %s is blocked", ErrPortBlocked, spec)
+	}
+
+	r.allowedPorts = append(r.allowedPorts, AllowedPort{Ports: spec, Description: description})
+	if err := r.Save(); err != nil {
+		r.allowedPorts = r.allowedPorts[:len(r.allowedPorts)-1]
+		return nil, err
+	}
+
+	return r.disallowedAssignments(), nil
+}
+
+// ListAllowedPorts returns a copy of the allowed ports
+func (r *Registry) ListAllowedPorts() []AllowedPort {
+	return slices.Clone(r.allowedPorts)
+}
+
+// isPortAllowed reports whether port is inside an allowed range, or whether
+// the registry has no allowed ports
+func (r *Registry) isPortAllowed(port int) bool {
+	if len(r.allowedPorts) == 0 {
+		return true
+	}
+	for _, ap := range r.allowedPorts {
+		if isPortInRange(port, ap.Ports) {
+			return true
+		}
+	}
+	return false
+}
+
+// disallowedAssignments returns the assignments outside every allowed range
+func (r *Registry) disallowedAssignments() []Assignment {
+	var found []Assignment
+	for _, a := range r.assignments {
+		if !r.isPortAllowed(a.Port) {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// rangesFullyBlocked reports whether every port in ranges is blocked
+func (r *Registry) rangesFullyBlocked(ranges []blockedRange) bool {
+	av := r.newAvailability()
+	for _, br := range ranges {
+		if av.blockedEnd(br.start) < br.end {
+			return false
+		}
+	}
+	return true
+}
+
+// validateAllowedPorts returns an error for each malformed or fully blocked
+// allowed entry and each assignment outside every allowed range
+func (r *Registry) validateAllowedPorts() []error {
+	var errs []error
+	for i, ap := range r.allowedPorts {
+		ranges, err := validateBlockedSpec(ap.Ports)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("allowed entry %d: %w", i, err))
+			continue
+		}
+		if r.rangesFullyBlocked(ranges) {
+			errs = append(errs, fmt.Errorf("%w: every port in allowed entry %s is blocked", ErrPortBlocked, ap.Ports))
+		}
+	}
+
+	for _, a := range r.disallowedAssignments() {
+		errs = append(errs, fmt.Errorf("%w: port %d assigned to '%s'", ErrPortNotAllowed, a.Port, a.Description))
+	}
+
+	return errs
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowPorts(t *testing.T) {
+	t.Run("restricts assignment to allowed ranges", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.BlockPorts("4000-4001", ""))
+		disallowed, err := reg.AllowPorts("4000-4002", "team range")
+		require.NoError(t, err)
+		assert.Empty(t, disallowed)
+		_, err = reg.AllowPorts("5000", "")
+		require.NoError(t, err)
+
+		assert.True(t, reg.IsPortAvailable(4002))
+		assert.False(t, reg.IsPortAvailable(4001))
+		assert.False(t, reg.IsPortAvailable(3100))
+
+		require.ErrorIs(t, reg.AssignPort(3100, "project1", ""), ErrPortNotAllowed)
+
+		// Auto-assignment skips to the allowed ranges
+		port, err := reg.AssignNextAvailable("project1", "")
+		require.NoError(t, err)
+		assert.Equal(t, 4002, port)
+		port, err = reg.AssignNextAvailable("project2", "")
+		require.NoError(t, err)
+		assert.Equal(t, 5000, port)
+		_, err = reg.AssignNextAvailable("project3", "")
+		require.ErrorIs(t, err, ErrNoPortsAvailable)
+
+		reloaded, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, []AllowedPort{{Ports: "4000-4002", Description: "team range"}, {Ports: "5000"}}, reloaded.ListAllowedPorts())
+		assert.Empty(t, reloaded.Validate())
+	})
+
+	t.Run("returns assignments left outside", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(3100, "project1", ""))
+
+		disallowed, err := reg.AllowPorts("4000-4010", "")
+		require.NoError(t, err)
+		assert.Equal(t, []int{3100}, assignmentPorts(disallowed))
+
+		errs := reg.Validate()
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], ErrPortNotAllowed)
+	})
+
+	t.Run("refuses fully blocked ranges", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.BlockPorts("4000-4005", ""))
+		require.NoError(t, reg.BlockPorts("4006", ""))
+
+		_, err := reg.AllowPorts("4002-4006", "")
+		require.ErrorIs(t, err, ErrPortBlocked)
+		assert.Empty(t, reg.ListAllowedPorts())
+
+		_, err = reg.AllowPorts("nope", "")
+		require.ErrorIs(t, err, ErrInvalidPortRange)
+	})
+
+	t.Run("no allowed ports allows every port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		assert.True(t, reg.IsPortAvailable(1))
+		assert.True(t, reg.IsPortAvailable(65535))
+	})
+}
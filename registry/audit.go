@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+)
+
+// Audit log operations
+const (
+	AuditAssign   = "assign"
+	AuditUnassign = "unassign"
+	AuditMove     = "move"
+)
+
+// AuditEntry is one line of the audit log
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	Operation   string    `json:"operation"`
+	Port        int       `json:"port"`
+	Description string    `json:"description,omitempty"`
+	Path        string    `json:"path,omitempty"`
+
+	// From is the previous port of a move
+	From int `json:"from,omitempty"`
+
+	// User is the name of the user that made the change, if known
+	User string `json:"user,omitempty"`
+}
+
+// SetAuditLog enables the audit log for this Registry instance and writes it
+// to path instead of the default next to the registry file. The setting is not
+// saved to the registry file.
+func (r *Registry) SetAuditLog(path string) {
+	r.auditPath = path
+}
+
+// AuditEnabled reports whether changes are appended to the audit log, either
+// because the registry file sets "audit" or because SetAuditLog was called
+func (r *Registry) AuditEnabled() bool {
+	return r.audit || r.auditPath != ""
+}
+
+// AuditLogPath returns the path of the audit log: the path given to
+// SetAuditLog, or the registry path with ".log" appended
+func (r *Registry) AuditLogPath() string {
+	if r.auditPath != "" {
+		return r.auditPath
+	}
+	return r.path + ".log"
+}
+
+// History returns the entries of the audit log, oldest first. A missing log
+// is empty.
+func (r *Registry) History() ([]AuditEntry, error) {
+	f, err := os.Open(r.AuditLogPath())
+	if os.IsNotExist(err) {
+		return []AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// recordAudit records a change that has been saved. In dry run mode, which
+// Transaction uses, entries are kept until flushAudit is called with them.
+func (r *Registry) recordAudit(entries ...AuditEntry) {
+	if !r.AuditEnabled() || len(entries) == 0 {
+		return
+	}
+
+	now := r.now().UTC().Truncate(time.Second)
+	username := currentUser()
+	for i := range entries {
+		entries[i].Time = now
+		entries[i].User = username
+	}
+
+	if r.dryRun {
+		r.pendingAudit = append(r.pendingAudit, entries...)
+		return
+	}
+	r.flushAudit(entries)
+}
+
+// flushAudit appends entries to the audit log. Auditing is best-effort:
+// failures are logged and never fail the change that was already saved.
+func (r *Registry) flushAudit(entries []AuditEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	var data []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			r.log().Warn("failed to write audit log", "error", err)
+			return
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	path := r.AuditLogPath()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, r.fileMode)
+	if err != nil {
+		r.log().Warn("failed to write audit log", "path", path, "error", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		r.log().Warn("failed to write audit log", "path", path, "error", err)
+	}
+}
+
+// auditEntries returns an entry for operation for each of assignments
+func auditEntries(operation string, assignments []Assignment) []AuditEntry {
+	entries := make([]AuditEntry, len(assignments))
+	for i, a := range assignments {
+		entries[i] = AuditEntry{Operation: operation, Port: a.Port, Description: a.Description, Path: a.Path}
+	}
+	return entries
+}
+
+// currentUser returns the name of the current user, or "" if it is unknown
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.SetAuditLog(filepath.Join(t.TempDir(), "audit.log"))
+
+	require.NoError(t, reg.AssignPort(3100, "web", ""))
+	require.NoError(t, reg.MovePort(3100, 3105))
+	_, err := reg.AssignBlock(2, "api", "")
+	require.NoError(t, err)
+	require.NoError(t, reg.UnassignPort(3105))
+
+	entries, err := reg.History()
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	assert.Equal(t, AuditEntry{Time: testNow, Operation: AuditAssign, Port: 3100, Description: "web", User: entries[0].User}, entries[0])
+	assert.Equal(t, AuditMove, entries[1].Operation)
+	assert.Equal(t, 3100, entries[1].From)
+	assert.Equal(t, 3105, entries[1].Port)
+	assert.Equal(t, []int{3100, 3101}, []int{entries[2].Port, entries[3].Port})
+	assert.Equal(t, AuditUnassign, entries[4].Operation)
+	assert.Equal(t, "web", entries[4].Description)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(3100, "web", ""))
+		assert.False(t, reg.AuditEnabled())
+		assert.NoFileExists(t, reg.AuditLogPath())
+	})
+
+	t.Run("enabled in registry file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":1,"audit":true,"assignments":[],"blockedPorts":[]}`), 0644))
+		reg, err := New(path)
+		require.NoError(t, err)
+
+		require.NoError(t, reg.AssignPort(3100, "web", ""))
+		assert.Equal(t, path+".log", reg.AuditLogPath())
+		entries, err := reg.History()
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"audit": true`)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetAuditLog(filepath.Join(t.TempDir(), "audit.log"))
+
+		require.Error(t, reg.Transaction(func(tx *Registry) error {
+			require.NoError(t, tx.AssignPort(3100, "web", ""))
+			return ErrPortBlocked
+		}))
+		entries, err := reg.History()
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		require.NoError(t, reg.Transaction(func(tx *Registry) error {
+			return tx.AssignPort(3100, "web", "")
+		}))
+		entries, err = reg.History()
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("unwritable log does not fail the change", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetAuditLog(filepath.Join(t.TempDir(), "missing", "audit.log"))
+
+		require.NoError(t, reg.AssignPort(3100, "web", ""))
+		_, ok := reg.GetAssignment(3100)
+		assert.True(t, ok)
+	})
+}
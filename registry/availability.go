@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"slices"
+	"sort"
+)
+
+// blockedRange is an inclusive range of blocked ports
+type blockedRange struct {
+	start, end int
+}
+
+// availability answers IsPortAvailable for many ports without rescanning the
+// registry for each one. It is a snapshot and must be rebuilt after changes.
+type availability struct {
+	// used holds every assigned port
+	used map[int]bool
+
+	// blocked holds the valid blocked ranges sorted by start and merged so they
+	// do not overlap
+	blocked []blockedRange
+
+	// allowed holds the valid allowed ranges, sorted and merged like blocked.
+	// If it is empty every port is allowed.
+	allowed []blockedRange
+}
+
+// newAvailability builds an availability snapshot of r in O(n log n) for n
+// assignments and blocked entries
+func (r *Registry) newAvailability() *availability {
+	av := &availability{used: make(map[int]bool, len(r.assignments))}
+	for _, a := range r.assignments {
+		av.used[a.Port] = true
+	}
+	for _, ia := range r.included {
+		av.used[ia.Port] = true
+	}
+
+	for _, bp := range r.blockedPorts {
+		ranges, err := parseBlockedSpec(bp.Ports)
+		if err != nil {
+			continue
+		}
+		av.blocked = append(av.blocked, ranges...)
+	}
+	av.blocked = mergeRanges(av.blocked)
+
+	for _, ap := range r.allowedPorts {
+		ranges, err := parseBlockedSpec(ap.Ports)
+		if err != nil {
+			continue
+		}
+		av.allowed = append(av.allowed, ranges...)
+	}
+	av.allowed = mergeRanges(av.allowed)
+
+	return av
+}
+
+// mergeRanges sorts ranges by start and merges overlapping and adjacent ranges
+// in place
+func mergeRanges(ranges []blockedRange) []blockedRange {
+	slices.SortFunc(ranges, func(a, b blockedRange) int {
+		return a.start - b.start
+	})
+
+	merged := ranges[:0]
+	for _, br := range ranges {
+		if n := len(merged); n > 0 && br.start <= merged[n-1].end+1 {
+			merged[n-1].end = max(merged[n-1].end, br.end)
+			continue
+		}
+		merged = append(merged, br)
+	}
+	return merged
+}
+
+// rangeIndex returns the index of the first of the sorted, merged ranges that
+// ends at or after port, or len(ranges) if there is none
+func rangeIndex(ranges []blockedRange, port int) int {
+	return sort.Search(len(ranges), func(i int) bool {
+		return ranges[i].end >= port
+	})
+}
+
+// blockedEnd returns the last port of the blocked range containing port, or -1
+// if port is not blocked
+func (av *availability) blockedEnd(port int) int {
+	i := rangeIndex(av.blocked, port)
+	if i < len(av.blocked) && av.blocked[i].start <= port {
+		return av.blocked[i].end
+	}
+	return -1
+}
+
+// rangesBlocked reports whether every port in ranges is blocked. It skips over
+// each blocked run instead of checking every port.
+func (av *availability) rangesBlocked(ranges []blockedRange) bool {
+	for _, br := range ranges {
+		for port := br.start; port <= br.end; {
+			end := av.blockedEnd(port)
+			if end == -1 {
+				return false
+			}
+			port = end + 1
+		}
+	}
+	return true
+}
+
+// nextAllowed returns the lowest allowed port at or after port, or -1 if there
+// is none
+func (av *availability) nextAllowed(port int) int {
+	if len(av.allowed) == 0 {
+		return port
+	}
+	i := rangeIndex(av.allowed, port)
+	if i == len(av.allowed) {
+		return -1
+	}
+	return max(port, av.allowed[i].start)
+}
+
+// isAvailable reports whether port is allowed and neither assigned nor blocked
+func (av *availability) isAvailable(port int) bool {
+	return !av.used[port] && av.blockedEnd(port) == -1 && av.nextAllowed(port) == port
+}
@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailability(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{{Port: 3100}, {Port: 3105}}
+	reg.blockedPorts = []BlockedPort{
+		{Ports: "4005-4010"},
+		{Ports: "4000-4004"},
+		{Ports: "4008-4020"},
+		{Ports: "5000"},
+		{Ports: "abc"},
+		{Ports: "6010-6000"},
+	}
+
+	av := reg.newAvailability()
+	assert.Equal(t, []blockedRange{{4000, 4020}, {5000, 5000}}, av.blocked)
+
+	for port := 3000; port <= 7000; port++ {
+		assert.Equal(t, reg.IsPortAvailable(port), av.isAvailable(port), "port %d", port)
+	}
+
+	assert.Equal(t, 4020, av.blockedEnd(4000))
+	assert.Equal(t, 5000, av.blockedEnd(5000))
+	assert.Equal(t, -1, av.blockedEnd(4021))
+
+	assert.True(t, av.rangesBlocked([]blockedRange{{4000, 4020}, {5000, 5000}}))
+	assert.True(t, av.rangesBlocked([]blockedRange{{4010, 4015}}))
+	assert.False(t, av.rangesBlocked([]blockedRange{{4015, 4021}}))
+	assert.False(t, av.rangesBlocked([]blockedRange{{4000, 4004}, {4999, 5000}}))
+}
@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ParseBlockList reads a list of blocked entries, one per line as
+// "<spec> [description]". The spec is a port, range, pattern, or
+// comma-separated list of them without spaces; the rest of the line is the
+// description. Blank lines and lines starting with # are skipped. An invalid
+// spec is an error naming its line.
+func ParseBlockList(r io.Reader) ([]BlockedPort, error) {
+	blocked := []BlockedPort{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		spec, description := text, ""
+		if i := strings.IndexFunc(text, unicode.IsSpace); i != -1 {
+			spec, description = text[:i], strings.TrimSpace(text[i:])
+		}
+		if _, err := validateBlockedSpec(spec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		blocked = append(blocked, BlockedPort{Ports: spec, Description: description})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read block list: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// BlockFromList blocks the entries of blocked and saves once. In ImportMerge
+// mode they are added like MergeBlockedPorts: entries whose ports are already
+// all blocked are skipped, as are entries covering an assigned port. In
+// ImportReplace mode the existing blocked entries are first removed, so the
+// registry ends up blocking exactly the listed entries that do not cover an
+// assigned port; the dropped entries are reported in Removed. Invalid specs
+// are an error and nothing is changed.
+func (r *Registry) BlockFromList(blocked []BlockedPort, mode ImportMode) (BlockListResult, error) {
+	switch mode {
+	case ImportMerge:
+		return r.MergeBlockedPorts(blocked)
+	case ImportReplace:
+	default:
+		return BlockListResult{}, fmt.Errorf("invalid import mode: %d", mode)
+	}
+
+	prev := r.blockedPorts
+	r.blockedPorts = []BlockedPort{}
+	result, err := r.MergeBlockedPorts(blocked)
+	if err != nil {
+		r.blockedPorts = prev
+		return BlockListResult{}, err
+	}
+
+	// MergeBlockedPorts only saves when it adds an entry
+	if len(result.Added) == 0 {
+		if err := r.Save(); err != nil {
+			r.blockedPorts = prev
+			return BlockListResult{}, err
+		}
+	}
+
+	for _, bp := range prev {
+		if !slices.Contains(r.blockedPorts, bp) {
+			result.Removed = append(result.Removed, bp)
+		}
+	}
+
+	return result, nil
+}
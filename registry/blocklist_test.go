@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlockList(t *testing.T) {
+	blocked, err := ParseBlockList(strings.NewReader(`
+# team blocked ports
+5432 postgres
+3000-3010	rails  dev servers
+40xx,4500
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []BlockedPort{
+		{Ports: "5432", Description: "postgres"},
+		{Ports: "3000-3010", Description: "rails  dev servers"},
+		{Ports: "40xx,4500"},
+	}, blocked)
+
+	_, err = ParseBlockList(strings.NewReader("5432\n70000 too high\n"))
+	require.ErrorIs(t, err, ErrInvalidPortRange)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestBlockFromList(t *testing.T) {
+	list := []BlockedPort{
+		{Ports: "5432", Description: "postgres"},
+		{Ports: "3100-3110", Description: "assigned"},
+		{Ports: "6000-6010", Description: "x11"},
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.BlockPorts("6000-6020", "existing"))
+		require.NoError(t, reg.AssignPort(3105, "web", ""))
+
+		result, err := reg.BlockFromList(list, ImportMerge)
+		require.NoError(t, err)
+		assert.Equal(t, []BlockedPort{{Ports: "5432", Description: "postgres"}}, result.Added)
+		assert.Equal(t, []BlockedPort{{Ports: "6000-6010", Description: "x11"}}, result.AlreadyBlocked)
+		assert.Equal(t, []int{3105}, assignmentPorts(result.Assigned))
+		assert.Empty(t, result.Removed)
+		assert.Len(t, reg.ListBlockedPorts(), 2)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.BlockPorts("7000", "old"))
+		require.NoError(t, reg.BlockPorts("6000", "kept"))
+		require.NoError(t, reg.AssignPort(3105, "web", ""))
+
+		result, err := reg.BlockFromList(list, ImportReplace)
+		require.NoError(t, err)
+		assert.Len(t, result.Added, 2)
+		assert.Equal(t, []int{3105}, assignmentPorts(result.Assigned))
+		assert.Equal(t, []BlockedPort{{Ports: "7000", Description: "old"}, {Ports: "6000", Description: "kept"}}, result.Removed)
+
+		reloaded, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, []BlockedPort{{Ports: "5432", Description: "postgres"}, {Ports: "6000-6010", Description: "x11"}}, reloaded.ListBlockedPorts())
+	})
+
+	t.Run("replace with nothing to add", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.BlockPorts("7000", "old"))
+
+		_, err := reg.BlockFromList(nil, ImportReplace)
+		require.NoError(t, err)
+
+		reloaded, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Empty(t, reloaded.ListBlockedPorts())
+	})
+
+	t.Run("invalid spec changes nothing", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.BlockPorts("7000", "old"))
+
+		_, err := reg.BlockFromList([]BlockedPort{{Ports: "bad"}}, ImportReplace)
+		require.ErrorIs(t, err, ErrInvalidPortRange)
+		assert.Equal(t, []BlockedPort{{Ports: "7000", Description: "old"}}, reg.ListBlockedPorts())
+	})
+}
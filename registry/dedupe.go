@@ -0,0 +1,72 @@
+package registry
+
+import "strings"
+
+// DedupeResult lists the assignments removed by Dedupe
+type DedupeResult struct {
+	// Duplicates are later assignments of a port and protocol that was already
+	// assigned
+	Duplicates []Assignment `json:"duplicates"`
+
+	// Invalid are assignments with an invalid port number
+	Invalid []Assignment `json:"invalid"`
+
+	// InvalidBlocked are blocked entries without ports
+	InvalidBlocked []BlockedPort `json:"invalidBlocked,omitempty"`
+}
+
+// Removed returns the number of assignments Dedupe removed
+func (d DedupeResult) Removed() int {
+	return len(d.Duplicates) + len(d.Invalid)
+}
+
+// Dedupe removes assignments with an invalid port, every assignment of a port
+// and protocol after the first, and blocked entries without ports, then saves.
+// Such entries can only come from hand-editing the registry file. Load the
+// registry WithLenientLoad to remove the entries New rejects.
+func (r *Registry) Dedupe() (DedupeResult, error) {
+	type portKey struct {
+		port     int
+		protocol string
+	}
+
+	result := DedupeResult{Duplicates: []Assignment{}, Invalid: []Assignment{}}
+	seen := make(map[portKey]bool, len(r.assignments))
+	kept := make([]Assignment, 0, len(r.assignments))
+	for _, a := range r.assignments {
+		if validatePort(a.Port) != nil {
+			result.Invalid = append(result.Invalid, a)
+			continue
+		}
+
+		key := portKey{a.Port, a.EffectiveProtocol()}
+		if seen[key] {
+			result.Duplicates = append(result.Duplicates, a)
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, a)
+	}
+
+	keptBlocked := make([]BlockedPort, 0, len(r.blockedPorts))
+	for _, bp := range r.blockedPorts {
+		if strings.TrimSpace(bp.Ports) == "" {
+			result.InvalidBlocked = append(result.InvalidBlocked, bp)
+			continue
+		}
+		keptBlocked = append(keptBlocked, bp)
+	}
+
+	if result.Removed() == 0 && len(result.InvalidBlocked) == 0 {
+		return result, nil
+	}
+
+	prev, prevBlocked := r.assignments, r.blockedPorts
+	r.assignments, r.blockedPorts = kept, keptBlocked
+	if err := r.Save(); err != nil {
+		r.assignments, r.blockedPorts = prev, prevBlocked
+		return DedupeResult{}, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupe(t *testing.T) {
+	t.Run("removes duplicate and invalid assignments", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Description: "first"},
+			{Port: 8000, Description: "second"},
+			{Port: 8000, Description: "dns", Protocol: ProtocolUDP},
+			{Port: 70000, Description: "invalid"},
+			{Port: 8001, Description: "other"},
+		}
+
+		result, err := reg.Dedupe()
+		require.NoError(t, err)
+		assert.Equal(t, DedupeResult{
+			Duplicates: []Assignment{{Port: 8000, Description: "second"}},
+			Invalid:    []Assignment{{Port: 70000, Description: "invalid"}},
+		}, result)
+		assert.Equal(t, 2, result.Removed())
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{
+			{Port: 8000, Description: "first"},
+			{Port: 8000, Description: "dns", Protocol: ProtocolUDP},
+			{Port: 8001, Description: "other"},
+		}, reg2.assignments)
+	})
+
+	t.Run("removes entries rejected by a strict load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[{"port":0,"description":"bad"},{"port":8000,"description":"good"}],"blockedPorts":[{"ports":" ","description":"empty"},{"ports":"9000"}]}`), 0644))
+
+		_, err := New(path)
+		require.ErrorIs(t, err, ErrMalformedRegistry)
+
+		reg, err := New(path, WithLenientLoad(true))
+		require.NoError(t, err)
+		result, err := reg.Dedupe()
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{{Port: 0, Description: "bad"}}, result.Invalid)
+		assert.Equal(t, []BlockedPort{{Ports: " ", Description: "empty"}}, result.InvalidBlocked)
+
+		reg2, err := New(path)
+		require.NoError(t, err)
+		assert.Equal(t, []int{8000}, assignmentPorts(reg2.assignments))
+		assert.Equal(t, []BlockedPort{{Ports: "9000"}}, reg2.blockedPorts)
+	})
+
+	t.Run("does not save when nothing is removed", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000}}
+
+		result, err := reg.Dedupe()
+		require.NoError(t, err)
+		assert.Zero(t, result.Removed())
+		assert.NoFileExists(t, reg.path)
+	})
+}
@@ -0,0 +1,59 @@
+package registry
+
+// DiffResult describes how the assignments of two registries differ
+type DiffResult struct {
+	// OnlyInA and OnlyInB are the assignments present in only one registry
+	OnlyInA []Assignment `json:"onlyInA"`
+	OnlyInB []Assignment `json:"onlyInB"`
+
+	// Changed holds ports assigned in both registries with a different
+	// description or path
+	Changed []AssignmentChange `json:"changed"`
+}
+
+// AssignmentChange is a port assigned in both registries of a diff
+type AssignmentChange struct {
+	A Assignment `json:"a"`
+	B Assignment `json:"b"`
+}
+
+// Empty reports whether the registries have the same assignments
+func (d DiffResult) Empty() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares the assignments of r (A) with other (B). Assignments are
+// matched by port and protocol. Results are sorted by port.
+func (r *Registry) Diff(other *Registry) DiffResult {
+	type key struct {
+		port     int
+		protocol string
+	}
+	keyOf := func(a Assignment) key {
+		return key{a.Port, a.EffectiveProtocol()}
+	}
+
+	inB := make(map[key]Assignment, len(other.assignments))
+	for _, b := range other.assignments {
+		inB[keyOf(b)] = b
+	}
+
+	d := DiffResult{OnlyInA: []Assignment{}, OnlyInB: []Assignment{}, Changed: []AssignmentChange{}}
+	inA := make(map[key]bool, len(r.assignments))
+	for _, a := range sortedAssignments(r.assignments) {
+		inA[keyOf(a)] = true
+		b, ok := inB[keyOf(a)]
+		if !ok {
+			d.OnlyInA = append(d.OnlyInA, a)
+		} else if a.Description != b.Description || a.Path != b.Path {
+			d.Changed = append(d.Changed, AssignmentChange{A: a, B: b})
+		}
+	}
+	for _, b := range sortedAssignments(other.assignments) {
+		if !inA[keyOf(b)] {
+			d.OnlyInB = append(d.OnlyInB, b)
+		}
+	}
+
+	return d
+}
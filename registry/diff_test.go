@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("reports ports only in one registry and changed ports", func(t *testing.T) {
+		a := createTestRegistry(t)
+		a.assignments = []Assignment{
+			{Port: 3102, Description: "changed", Path: "/a"},
+			{Port: 3100, Description: "same", Path: "/same"},
+			{Port: 3101, Description: "only a"},
+			{Port: 3103, Description: "dns", Protocol: ProtocolUDP},
+		}
+
+		b := createTestRegistry(t)
+		b.assignments = []Assignment{
+			{Port: 3100, Description: "same", Path: "/same"},
+			{Port: 3102, Description: "changed", Path: "/b"},
+			{Port: 3103, Description: "dns", Protocol: ProtocolTCP},
+			{Port: 3104, Description: "only b"},
+		}
+
+		assert.Equal(t, DiffResult{
+			OnlyInA: []Assignment{
+				{Port: 3101, Description: "only a"},
+				{Port: 3103, Description: "dns", Protocol: ProtocolUDP},
+			},
+			OnlyInB: []Assignment{
+				{Port: 3103, Description: "dns", Protocol: ProtocolTCP},
+				{Port: 3104, Description: "only b"},
+			},
+			Changed: []AssignmentChange{
+				{A: Assignment{Port: 3102, Description: "changed", Path: "/a"}, B: Assignment{Port: 3102, Description: "changed", Path: "/b"}},
+			},
+		}, a.Diff(b))
+	})
+
+	t.Run("is empty for identical registries", func(t *testing.T) {
+		a := createTestRegistry(t)
+		a.assignments = []Assignment{{Port: 3100, Description: "same"}}
+		b := createTestRegistry(t)
+		b.assignments = []Assignment{{Port: 3100, Description: "same", Tags: []string{"ignored"}}}
+
+		assert.True(t, a.Diff(b).Empty())
+	})
+}
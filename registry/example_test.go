@@ -0,0 +1,72 @@
+package registry_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/jackc/portreg/registry"
+)
+
+func Example() {
+	dir, err := os.MkdirTemp("", "portreg")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	reg, err := registry.New(filepath.Join(dir, "ports.json"), registry.WithStartPort(8000), registry.WithEndPort(8099))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := reg.InitWith([]registry.BlockedPort{{Ports: "8000-8009", Description: "reserved"}}); err != nil {
+		log.Fatal(err)
+	}
+
+	next, err := reg.NextAvailable()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("next available:", next)
+
+	port, err := reg.AssignNext(registry.Assignment{Description: "api", Path: "/src/api"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("assigned:", port)
+
+	for _, a := range reg.FindByPath("/src/api") {
+		fmt.Printf("%s uses %d\n", a.Description, a.Port)
+	}
+
+	if a, ok := reg.GetAssignment(port); ok {
+		fmt.Println("port", port, "is assigned to", a.Description)
+	}
+
+	for _, bp := range reg.ListBlockedPorts() {
+		fmt.Printf("blocked: %s (%s)\n", bp.Ports, bp.Description)
+	}
+
+	// Output:
+	// next available: 8010
+	// assigned: 8010
+	// api uses 8010
+	// port 8010 is assigned to api
+	// blocked: 8000-8009 (reserved)
+}
+
+func ExampleNew() {
+	// A read-only registry never writes the file, which suits tools that only
+	// look up ports
+	reg, err := registry.New("/path/that/does/not/exist.json", registry.WithReadOnly(true))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = reg.AssignPort(8000, "api", "")
+	fmt.Println(err)
+
+	// Output:
+	// registry is in read-only mode
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package registry
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileModeUmask(t *testing.T) {
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+
+	t.Run("default mode respects the umask", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Save())
+
+		info, err := os.Stat(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("explicit mode overrides the umask", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetFileMode(0644)
+		require.NoError(t, reg.Save())
+
+		info, err := os.Stat(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	})
+}
@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImportMode controls how Import combines imported assignments with existing ones
+type ImportMode int
+
+const (
+	// ImportMerge adds imported assignments to the existing assignments
+	ImportMerge ImportMode = iota
+
+	// ImportReplace replaces all existing assignments with the imported assignments
+	ImportReplace
+)
+
+// ImportConflict describes an imported assignment that cannot be applied
+type ImportConflict struct {
+	// Index is the position of the assignment in the imported slice
+	Index      int
+	Assignment Assignment
+	Err        error
+}
+
+// ImportError is returned by Import when one or more imported assignments conflict
+type ImportError struct {
+	Conflicts []ImportConflict
+}
+
+func (e *ImportError) Error() string {
+	msgs := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		msgs = append(msgs, c.Err.Error())
+	}
+	return fmt.Sprintf("import has %d conflict(s): %s", len(e.Conflicts), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of each conflict so errors.Is can match them
+func (e *ImportError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		errs = append(errs, c.Err)
+	}
+	return errs
+}
+
+// Import adds assignments to the registry. In ImportMerge mode they are added to
+// the existing assignments and ports that are already assigned or blocked are
+// conflicts. In ImportReplace mode they replace all existing assignments and
+// only blocked ports are conflicts. Duplicate ports (of the same protocol)
+// within the import, invalid ports and unknown protocols are always conflicts.
+// If there are any conflicts an *ImportError listing all of them is returned
+// and nothing is changed. Like Assign, Import stores paths in absolute, cleaned
+// form and sets CreatedAt when it is zero.
+func (r *Registry) Import(assignments []Assignment, mode ImportMode) error {
+	if mode != ImportMerge && mode != ImportReplace {
+		return fmt.Errorf("invalid import mode: %d", mode)
+	}
+
+	var conflicts []ImportConflict
+	seen := make(map[importKey]bool)
+
+	imported := make([]Assignment, 0, len(assignments))
+	for i, a := range assignments {
+		if err := r.checkImport(a, mode, seen); err != nil {
+			conflicts = append(conflicts, ImportConflict{Index: i, Assignment: a, Err: err})
+		}
+		seen[importKey{a.Port, a.EffectiveProtocol()}] = true
+
+		a.Path = canonicalPath(a.Path)
+		if a.CreatedAt.IsZero() {
+			a.CreatedAt = r.createdAt()
+		}
+		imported = append(imported, a)
+	}
+
+	if len(conflicts) > 0 {
+		return &ImportError{Conflicts: conflicts}
+	}
+
+	prev := r.assignments
+	if mode == ImportReplace {
+		r.assignments = imported
+	} else {
+		r.assignments = append(r.assignments[:len(r.assignments):len(r.assignments)], imported...)
+	}
+
+	if err := r.Save(); err != nil {
+		r.assignments = prev
+		return err
+	}
+
+	if mode == ImportReplace {
+		r.recordAudit(auditEntries(AuditUnassign, prev)...)
+	}
+	r.recordAudit(auditEntries(AuditAssign, imported)...)
+	return nil
+}
+
+// importKey identifies an imported assignment by port and protocol
+type importKey struct {
+	port     int
+	protocol string
+}
+
+// checkImport checks whether an imported assignment can be applied. seen holds
+// the ports and protocols of the assignments imported before it.
+func (r *Registry) checkImport(a Assignment, mode ImportMode, seen map[importKey]bool) error {
+	if err := validatePort(a.Port); err != nil {
+		return err
+	}
+
+	if err := validateProtocol(a.Protocol); err != nil {
+		return err
+	}
+
+	if seen[importKey{a.Port, a.EffectiveProtocol()}] {
+		return fmt.Errorf("%w: port %d/%s is imported more than once", ErrPortAlreadyAssigned, a.Port, a.EffectiveProtocol())
+	}
+
+	if mode == ImportMerge {
+		return r.checkAssignable(a.Port, a.EffectiveProtocol())
+	}
+
+	if r.isPortBlocked(a.Port) {
+		return fmt.Errorf("%w: port %d", ErrPortBlocked, a.Port)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	t.Run("merges assignments", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "existing"}}
+
+		err := reg.Import([]Assignment{{Port: 8001, Description: "new1"}, {Port: 8002, Description: "new2"}}, ImportMerge)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Assignment{
+			{Port: 8000, Description: "existing"},
+			{Port: 8001, Description: "new1", CreatedAt: testNow},
+			{Port: 8002, Description: "new2", CreatedAt: testNow},
+		}, reg.assignments)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, reg.assignments, reg2.assignments)
+	})
+
+	t.Run("merge reports all conflicts and changes nothing", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "existing"}}
+		reg.blockedPorts = []BlockedPort{{Ports: "9000-9010"}}
+
+		err := reg.Import([]Assignment{
+			{Port: 8000},
+			{Port: 8001},
+			{Port: 9005},
+			{Port: 8001},
+			{Port: 70000},
+		}, ImportMerge)
+
+		var importErr *ImportError
+		require.True(t, errors.As(err, &importErr))
+		require.Len(t, importErr.Conflicts, 4)
+		assert.Equal(t, 0, importErr.Conflicts[0].Index)
+		assert.ErrorIs(t, importErr.Conflicts[0].Err, ErrPortAlreadyAssigned)
+		assert.Equal(t, 2, importErr.Conflicts[1].Index)
+		assert.ErrorIs(t, importErr.Conflicts[1].Err, ErrPortBlocked)
+		assert.Equal(t, 3, importErr.Conflicts[2].Index)
+		assert.ErrorIs(t, importErr.Conflicts[2].Err, ErrPortAlreadyAssigned)
+		assert.Equal(t, 4, importErr.Conflicts[3].Index)
+		assert.ErrorIs(t, importErr.Conflicts[3].Err, ErrInvalidPort)
+
+		assert.ErrorIs(t, err, ErrPortBlocked)
+		assert.Equal(t, []Assignment{{Port: 8000, Description: "existing"}}, reg.assignments)
+	})
+
+	t.Run("replaces assignments", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "existing"}}
+
+		err := reg.Import([]Assignment{{Port: 8000, Description: "replaced"}, {Port: 8001}}, ImportReplace)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Assignment{{Port: 8000, Description: "replaced", CreatedAt: testNow}, {Port: 8001, CreatedAt: testNow}}, reg.assignments)
+	})
+
+	t.Run("conflicts are per protocol", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "existing"}}
+
+		err := reg.Import([]Assignment{
+			{Port: 8000, Description: "stats", Protocol: ProtocolUDP},
+			{Port: 8001, Description: "web"},
+			{Port: 8001, Description: "dns", Protocol: ProtocolUDP},
+		}, ImportMerge)
+		require.NoError(t, err)
+		assert.Equal(t, []int{8000, 8000, 8001, 8001}, assignmentPorts(reg.assignments))
+
+		err = reg.Import([]Assignment{{Port: 8000, Protocol: ProtocolUDP}}, ImportMerge)
+		assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+	})
+
+	t.Run("applies the rules of Assign", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.Import([]Assignment{{Port: 8000, Protocol: "sctp"}}, ImportMerge)
+		assert.ErrorIs(t, err, ErrInvalidProtocol)
+
+		err = reg.Import([]Assignment{{Port: 8000, Path: "/tmp/../tmp/x/"}}, ImportMerge)
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{{Port: 8000, Path: "/tmp/x", CreatedAt: testNow}}, reg.assignments)
+	})
+
+	t.Run("keeps assignments when saving fails", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "existing"}}
+		reg.SetReadOnly(true)
+
+		err := reg.Import([]Assignment{{Port: 8001}}, ImportMerge)
+		require.ErrorIs(t, err, ErrReadOnly)
+		assert.Equal(t, []int{8000}, assignmentPorts(reg.assignments))
+
+		err = reg.Import([]Assignment{{Port: 8001}}, ImportReplace)
+		require.ErrorIs(t, err, ErrReadOnly)
+		assert.Equal(t, []int{8000}, assignmentPorts(reg.assignments))
+	})
+
+	t.Run("replace fails on blocked port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000}}
+		reg.blockedPorts = []BlockedPort{{Ports: "9000"}}
+
+		err := reg.Import([]Assignment{{Port: 9000}}, ImportReplace)
+		assert.ErrorIs(t, err, ErrPortBlocked)
+		assert.Equal(t, []Assignment{{Port: 8000}}, reg.assignments)
+	})
+}
@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ErrIncludeCycle is returned when registry files include each other
+var ErrIncludeCycle = errors.New("registry include cycle")
+
+// IncludedAssignment is an assignment read from a registry file listed in
+// "includes". Included assignments make their ports unavailable but are never
+// changed or saved by the including registry.
+type IncludedAssignment struct {
+	Assignment
+
+	// Source is the path of the registry file the assignment was read from
+	Source string `json:"source" yaml:"source"`
+}
+
+// Includes returns the include paths stored in the registry file
+func (r *Registry) Includes() []string {
+	return slices.Clone(r.includes)
+}
+
+// IncludedAssignments returns the assignments read from included registry
+// files, including files those files include
+func (r *Registry) IncludedAssignments() []IncludedAssignment {
+	return slices.Clone(r.included)
+}
+
+// includedAssignment returns the included assignment of port for protocol
+func (r *Registry) includedAssignment(port int, protocol string) (IncludedAssignment, bool) {
+	for _, ia := range r.included {
+		if ia.Port == port && ia.EffectiveProtocol() == protocol {
+			return ia, true
+		}
+	}
+	return IncludedAssignment{}, false
+}
+
+// loadIncludes reads the assignments of the registry files included by the
+// file at path. Relative include paths are relative to the directory of the
+// including file. A file reachable through more than one include is read
+// once; a file that includes itself, directly or indirectly, is an error
+// wrapping ErrIncludeCycle.
+func loadIncludes(path string, includes []string) ([]IncludedAssignment, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var included []IncludedAssignment
+	visited := map[string]bool{}
+
+	var walk func(from string, includes, stack []string) error
+	walk = func(from string, includes, stack []string) error {
+		for _, inc := range includes {
+			incPath := inc
+			if !filepath.IsAbs(incPath) {
+				incPath = filepath.Join(filepath.Dir(from), incPath)
+			}
+			incPath = filepath.Clean(incPath)
+
+			if slices.Contains(stack, incPath) {
+				return fmt.Errorf("%w: %s includes %s", ErrIncludeCycle, from, incPath)
+			}
+			if visited[incPath] {
+				continue
+			}
+			visited[incPath] = true
+
+			data, err := os.ReadFile(incPath)
+			if err != nil {
+				return fmt.Errorf("failed to read included registry: %w", err)
+			}
+			regData, err := (&Registry{path: incPath}).decode(data)
+			if err != nil {
+				return fmt.Errorf("included registry %s: %w", incPath, err)
+			}
+
+			for _, a := range regData.Assignments {
+				included = append(included, IncludedAssignment{Assignment: a, Source: incPath})
+			}
+
+			if err := walk(incPath, regData.Includes, append(stack, incPath)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(abs, includes, []string{abs}); err != nil {
+		return nil, err
+	}
+
+	return included, nil
+}
+
+// validateIncludes returns an error wrapping ErrPortAlreadyAssigned for each
+// included assignment whose port and protocol are already assigned by the
+// registry or an earlier included file
+func (r *Registry) validateIncludes() []error {
+	type portKey struct {
+		port     int
+		protocol string
+	}
+
+	sources := make(map[portKey]IncludedAssignment, len(r.assignments)+len(r.included))
+	for _, a := range r.assignments {
+		sources[portKey{a.Port, a.EffectiveProtocol()}] = IncludedAssignment{Assignment: a, Source: r.path}
+	}
+
+	var errs []error
+	for _, ia := range r.included {
+		key := portKey{ia.Port, ia.EffectiveProtocol()}
+		if prev, ok := sources[key]; ok {
+			if prev.Source != ia.Source {
+				errs = append(errs, fmt.Errorf("%w: port %d is assigned to both '%s' in %s and '%s' in %s", ErrPortAlreadyAssigned, ia.Port, prev.Description, prev.Source, ia.Description, ia.Source))
+			}
+			continue
+		}
+		sources[key] = ia
+	}
+
+	return errs
+}
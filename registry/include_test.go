@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncludes(t *testing.T) {
+	writeFile := func(t *testing.T, path, data string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	}
+
+	t.Run("included ports are unavailable and not saved", func(t *testing.T) {
+		dir := t.TempDir()
+		teamA := filepath.Join(dir, "teams", "team-a.json")
+		writeFile(t, teamA, `{"assignments":[{"port":3100,"description":"team-a-web"}],"blockedPorts":[]}`)
+		path := filepath.Join(dir, "global", "registry.json")
+		writeFile(t, path, `{"includes":["../teams/team-a.json"],"assignments":[],"blockedPorts":[]}`)
+
+		reg, err := New(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"../teams/team-a.json"}, reg.Includes())
+		assert.Equal(t, []IncludedAssignment{{Assignment: Assignment{Port: 3100, Description: "team-a-web"}, Source: teamA}}, reg.IncludedAssignments())
+		assert.False(t, reg.IsPortAvailable(3100))
+
+		err = reg.AssignPort(3100, "project1", "")
+		var conflict *PortConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, "team-a-web", conflict.Assignment.Description)
+
+		port, err := reg.AssignNextAvailable("project1", "")
+		require.NoError(t, err)
+		assert.Equal(t, 3101, port)
+
+		reloaded, err := New(path)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3101}, assignmentPorts(reloaded.ListAssignments()))
+		assert.Equal(t, []string{"../teams/team-a.json"}, reloaded.Includes())
+
+		data, err := os.ReadFile(teamA)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"assignments":[{"port":3100,"description":"team-a-web"}],"blockedPorts":[]}`, string(data))
+	})
+
+	t.Run("nested includes", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "b.json"), `{"assignments":[{"port":3200}],"blockedPorts":[]}`)
+		writeFile(t, filepath.Join(dir, "a.json"), `{"includes":["b.json"],"assignments":[{"port":3100}],"blockedPorts":[]}`)
+		path := filepath.Join(dir, "registry.json")
+		writeFile(t, path, `{"includes":["a.json","b.json"],"assignments":[],"blockedPorts":[]}`)
+
+		reg, err := New(path)
+		require.NoError(t, err)
+		included := reg.IncludedAssignments()
+		require.Len(t, included, 2)
+		assert.Equal(t, 3100, included[0].Port)
+		assert.Equal(t, 3200, included[1].Port)
+		assert.Empty(t, reg.Validate())
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "a.json"), `{"includes":["registry.json"],"assignments":[],"blockedPorts":[]}`)
+		path := filepath.Join(dir, "registry.json")
+		writeFile(t, path, `{"includes":["a.json"],"assignments":[],"blockedPorts":[]}`)
+
+		_, err := New(path)
+		require.ErrorIs(t, err, ErrIncludeCycle)
+	})
+
+	t.Run("missing include", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		writeFile(t, path, `{"includes":["missing.json"],"assignments":[],"blockedPorts":[]}`)
+
+		_, err := New(path)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("conflicting ports are reported by Validate", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "a.json"), `{"assignments":[{"port":3100,"description":"a-web"}],"blockedPorts":[]}`)
+		writeFile(t, filepath.Join(dir, "b.json"), `{"assignments":[{"port":3100,"description":"b-web"},{"port":3200,"description":"b-api"}],"blockedPorts":[]}`)
+		path := filepath.Join(dir, "registry.json")
+		writeFile(t, path, `{"includes":["a.json","b.json"],"assignments":[{"port":3200,"description":"api"}],"blockedPorts":[]}`)
+
+		reg, err := New(path)
+		require.NoError(t, err)
+		errs := reg.Validate()
+		require.Len(t, errs, 2)
+		for _, err := range errs {
+			assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+		}
+		assert.Contains(t, errs[0].Error(), "'a-web'")
+		assert.Contains(t, errs[1].Error(), "'api'")
+	})
+}
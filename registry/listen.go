@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"net"
+	"strconv"
+	"time"
+)
+
+// listenCheckTimeout is how long CheckPortListening waits for a connection
+const listenCheckTimeout = 200 * time.Millisecond
+
+// CheckPortListening reports whether something is accepting TCP connections on
+// the port on 127.0.0.1
+func CheckPortListening(port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), listenCheckTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// CheckPortBindable reports whether a TCP listener can be opened on the port.
+// The listener is closed immediately.
+func CheckPortBindable(port int) bool {
+	ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
+
+// CheckListening reports whether something is using the port for protocol on
+// this machine. TCP ports are checked by connecting to them. UDP is
+// connectionless, so a UDP port is in use if it cannot be bound.
+func CheckListening(port int, protocol string) bool {
+	if protocol == ProtocolUDP {
+		return !CheckBindable(port, protocol)
+	}
+	return CheckPortListening(port)
+}
+
+// CheckBindable reports whether the port can be bound for protocol. The socket
+// is closed immediately.
+func CheckBindable(port int, protocol string) bool {
+	if protocol != ProtocolUDP {
+		return CheckPortBindable(port)
+	}
+
+	conn, err := net.ListenPacket("udp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
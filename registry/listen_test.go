@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPortListening(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	assert.True(t, CheckPortListening(port))
+
+	require.NoError(t, ln.Close())
+	assert.False(t, CheckPortListening(port))
+}
+
+func TestCheckPortBindable(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	assert.False(t, CheckPortBindable(port))
+
+	require.NoError(t, ln.Close())
+	assert.True(t, CheckPortBindable(port))
+}
+
+func TestCheckListeningUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", ":0")
+	require.NoError(t, err)
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+
+	assert.True(t, CheckListening(port, ProtocolUDP))
+	assert.False(t, CheckBindable(port, ProtocolUDP))
+
+	require.NoError(t, conn.Close())
+	assert.False(t, CheckListening(port, ProtocolUDP))
+	assert.True(t, CheckBindable(port, ProtocolUDP))
+}
+
+func TestVerify(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	t.Run("assign fails on port in use", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetVerify(true)
+
+		err := reg.AssignPort(port, "project", "")
+		assert.ErrorIs(t, err, ErrPortInUse)
+		assert.Empty(t, reg.assignments)
+	})
+
+	t.Run("assign ignores OS without verify", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		require.NoError(t, reg.AssignPort(port, "project", ""))
+	})
+
+	t.Run("auto-assign skips port in use", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetVerify(true)
+		reg.SetStartPort(port)
+
+		assigned, err := reg.AssignNextAvailable("project", "")
+		require.NoError(t, err)
+		assert.NotEqual(t, port, assigned)
+	})
+}
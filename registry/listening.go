@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tcpListenState is the st value of a listening socket in /proc/net/tcp
+const tcpListenState = "0A"
+
+// ParseProcNetTCP reads a Linux /proc/net/tcp or /proc/net/tcp6 table and
+// returns the local ports of listening sockets, sorted and without duplicates
+func ParseProcNetTCP(r io.Reader) ([]int, error) {
+	ports := []int{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] != tcpListenState {
+			continue
+		}
+
+		_, portHex, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseUint(portHex, 16, 16)
+		if err != nil || validatePort(int(port)) != nil {
+			continue
+		}
+		ports = append(ports, int(port))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read TCP sockets: %w", err)
+	}
+
+	slices.Sort(ports)
+	return slices.Compact(ports), nil
+}
@@ -0,0 +1,35 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+)
+
+// ListeningPorts returns the TCP ports that currently have a listener on this
+// machine, read from /proc/net/tcp and /proc/net/tcp6
+func ListeningPorts() ([]int, error) {
+	ports := []int{}
+	for _, name := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		f, err := os.Open(name)
+		if err != nil {
+			// IPv6 may be disabled
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list listening ports: %w", err)
+		}
+
+		found, err := ParseProcNetTCP(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, found...)
+	}
+
+	slices.Sort(ports)
+	return slices.Compact(ports), nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ListeningPorts is only supported on Linux
+func ListeningPorts() ([]int, error) {
+	return nil, fmt.Errorf("listing listening ports is not supported on %s: %w", runtime.GOOS, errors.ErrUnsupported)
+}
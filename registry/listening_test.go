@@ -0,0 +1,22 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcNetTCP(t *testing.T) {
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 662 1 0000000091c6e787 100 0 0 10 0
+   1: 0100007F:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000 65534        0 907 1 00000000bceef720 100 0 0 10 0
+   2: 0100007F:AF0A 0100007F:0CEA 01 00000000:00000000 02:00000EC1 00000000     0        0 114069 2 000000005a77036d 20 4 0 19 -1
+   3: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 663 1 0000000091c6e787 100 0 0 10 0
+`
+
+	ports, err := ParseProcNetTCP(strings.NewReader(table))
+	require.NoError(t, err)
+	assert.Equal(t, []int{3306, 8080}, ports)
+}
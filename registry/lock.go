@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrAlreadyLocked is returned when Lock is called on a Registry that already holds the lock
+var ErrAlreadyLocked = errors.New("registry is already locked")
+
+// Lock acquires an exclusive advisory lock on the registry and reloads it from
+// disk so the in-memory state reflects any changes made by other processes
+// while the lock was not held. Hold the lock across a read-modify-write cycle
+// (e.g. AssignPort) so concurrent processes serialize correctly. Release it
+// with Unlock.
+func (r *Registry) Lock() error {
+	if r.lockFile != nil {
+		return ErrAlreadyLocked
+	}
+
+	f, err := acquireLock(r.lockPath())
+	if err != nil {
+		return err
+	}
+
+	r.lockFile = f
+
+	// Reload while holding the lock
+	if err := r.Reload(); err != nil {
+		r.Unlock()
+		return fmt.Errorf("failed to load registry: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock releases the lock acquired by Lock. It is a no-op if the lock is not held.
+func (r *Registry) Unlock() error {
+	if r.lockFile == nil {
+		return nil
+	}
+
+	f := r.lockFile
+	r.lockFile = nil
+
+	unlockErr := unlockFile(f)
+	closeErr := f.Close()
+	if unlockErr != nil {
+		return fmt.Errorf("failed to unlock registry: %w", unlockErr)
+	}
+	return closeErr
+}
+
+// acquireLock opens the lock file at path, creating it and its directory if
+// needed, and blocks until an exclusive lock is held on it
+func acquireLock(path string) (*os.File, error) {
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock registry: %w", err)
+	}
+
+	return f, nil
+}
+
+// lockPath returns the path of the lock file for the registry
+func (r *Registry) lockPath() string {
+	return r.path + ".lock"
+}
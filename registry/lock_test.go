@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	t.Run("reloads registry when locked", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg1, err := New(tempFile)
+		require.NoError(t, err)
+
+		reg2, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg2.AssignPort(8000, "project1", ""))
+
+		require.NoError(t, reg1.Lock())
+		defer reg1.Unlock()
+		assert.Len(t, reg1.assignments, 1)
+	})
+
+	t.Run("fails when already locked", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		require.NoError(t, reg.Lock())
+		assert.ErrorIs(t, reg.Lock(), ErrAlreadyLocked)
+		require.NoError(t, reg.Unlock())
+		require.NoError(t, reg.Unlock())
+	})
+
+	t.Run("serializes concurrent assignments", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		const workers = 10
+
+		var wg sync.WaitGroup
+		ports := make([]int, workers)
+		errs := make([]error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				reg, err := New(tempFile)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				if err := reg.Lock(); err != nil {
+					errs[i] = err
+					return
+				}
+				defer reg.Unlock()
+
+				ports[i], errs[i] = reg.AssignNextAvailable("worker", "")
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Len(t, reg.assignments, workers)
+		assert.ElementsMatch(t, []int{3100, 3101, 3102, 3103, 3104, 3105, 3106, 3107, 3108, 3109}, ports)
+	})
+}
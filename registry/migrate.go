@@ -0,0 +1,38 @@
+package registry
+
+import "fmt"
+
+// CurrentVersion is the registry file format version written by Save. Files
+// without a version are version 1.
+const CurrentVersion = 1
+
+// migrations upgrade registry data one version at a time. migrations[i]
+// upgrades version i+1 to version i+2, so len(migrations) must be
+// CurrentVersion-1. Add a migration here whenever the file format changes in a
+// way older data must be converted for.
+var migrations = []func(*registryData) error{}
+
+// migrate upgrades data loaded from a registry file to CurrentVersion in
+// memory. It returns ErrUnsupportedVersion for files written by a newer
+// version of portreg.
+func migrate(data *registryData) error {
+	if data.Version == 0 {
+		data.Version = 1
+	}
+
+	if data.Version < 0 {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, data.Version)
+	}
+	if data.Version > CurrentVersion {
+		return fmt.Errorf("%w: %d (this portreg supports up to version %d; upgrade portreg to use this registry)", ErrUnsupportedVersion, data.Version, CurrentVersion)
+	}
+
+	for data.Version < CurrentVersion {
+		if err := migrations[data.Version-1](data); err != nil {
+			return fmt.Errorf("failed to migrate registry from version %d: %w", data.Version, err)
+		}
+		data.Version++
+	}
+
+	return nil
+}
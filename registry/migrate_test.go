@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersion(t *testing.T) {
+	t.Run("loads files without a version as version 1", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"assignments":[{"port":8000}],"blockedPorts":[]}`), 0644))
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{{Port: 8000}}, reg.assignments)
+	})
+
+	t.Run("saves current version", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Save())
+
+		data, err := os.ReadFile(reg.path)
+		require.NoError(t, err)
+		var regData registryData
+		require.NoError(t, json.Unmarshal(data, &regData))
+		assert.Equal(t, CurrentVersion, regData.Version)
+	})
+
+	t.Run("rejects newer versions", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"version":99,"assignments":[],"blockedPorts":[]}`), 0644))
+
+		_, err := New(tempFile)
+		assert.ErrorIs(t, err, ErrUnsupportedVersion)
+		assert.ErrorContains(t, err, "upgrade portreg")
+	})
+
+	t.Run("has a migration for every older version", func(t *testing.T) {
+		assert.Len(t, migrations, CurrentVersion-1)
+	})
+}
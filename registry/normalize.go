@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NormalizeBlockedPorts merges overlapping and adjacent blocked ranges into
+// non-overlapping entries sorted by starting port. The descriptions of merged
+// entries are joined with "; ". Patterns such as "30xx" and entries that cannot
+// be parsed are kept as-is after the normalized entries.
+func (r *Registry) NormalizeBlockedPorts() error {
+	r.blockedPorts = normalizeBlockedPorts(r.blockedPorts)
+	return r.Save()
+}
+
+// normalizeBlockedPorts returns the normalized form of blocked
+func normalizeBlockedPorts(blocked []BlockedPort) []BlockedPort {
+	type portRange struct {
+		start, end   int
+		descriptions []string
+	}
+
+	var ranges []portRange
+	var unparsed []BlockedPort
+	for _, bp := range blocked {
+		start, end, err := parsePortRange(bp.Ports)
+		if err != nil || start > end {
+			unparsed = append(unparsed, bp)
+			continue
+		}
+		pr := portRange{start: start, end: end}
+		if bp.Description != "" {
+			pr.descriptions = []string{bp.Description}
+		}
+		ranges = append(ranges, pr)
+	}
+
+	slices.SortStableFunc(ranges, func(a, b portRange) int {
+		return a.start - b.start
+	})
+
+	var merged []portRange
+	for _, pr := range ranges {
+		if n := len(merged); n > 0 && pr.start <= merged[n-1].end+1 {
+			last := &merged[n-1]
+			last.end = max(last.end, pr.end)
+			for _, d := range pr.descriptions {
+				if !slices.Contains(last.descriptions, d) {
+					last.descriptions = append(last.descriptions, d)
+				}
+			}
+			continue
+		}
+		merged = append(merged, pr)
+	}
+
+	normalized := make([]BlockedPort, 0, len(merged)+len(unparsed))
+	for _, pr := range merged {
+		spec := strconv.Itoa(pr.start)
+		if pr.end != pr.start {
+			spec += "-" + strconv.Itoa(pr.end)
+		}
+		normalized = append(normalized, BlockedPort{
+			Ports:       spec,
+			Description: strings.Join(pr.descriptions, "; "),
+		})
+	}
+
+	return append(normalized, unparsed...)
+}
@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeBlockedPorts(t *testing.T) {
+	tests := []struct {
+		blocked    []BlockedPort
+		normalized []BlockedPort
+		desc       string
+	}{
+		{
+			[]BlockedPort{{Ports: "3000-3010", Description: "Rails"}, {Ports: "3011", Description: "Rails"}},
+			[]BlockedPort{{Ports: "3000-3011", Description: "Rails"}},
+			"adjacent single port",
+		},
+		{
+			[]BlockedPort{{Ports: "3000-3010", Description: "Rails"}, {Ports: "3005-3020", Description: "Node"}},
+			[]BlockedPort{{Ports: "3000-3020", Description: "Rails; Node"}},
+			"overlapping ranges",
+		},
+		{
+			[]BlockedPort{{Ports: "3000-3020"}, {Ports: "3005-3010", Description: "inner"}},
+			[]BlockedPort{{Ports: "3000-3020", Description: "inner"}},
+			"contained range",
+		},
+		{
+			[]BlockedPort{{Ports: "3307"}, {Ports: "3306"}, {Ports: "3308"}},
+			[]BlockedPort{{Ports: "3306-3308"}},
+			"contiguous single ports",
+		},
+		{
+			[]BlockedPort{{Ports: "8080", Description: "HTTP"}, {Ports: "3306", Description: "MySQL"}, {Ports: "5432", Description: "PostgreSQL"}},
+			[]BlockedPort{{Ports: "3306", Description: "MySQL"}, {Ports: "5432", Description: "PostgreSQL"}, {Ports: "8080", Description: "HTTP"}},
+			"disjoint ports are sorted",
+		},
+		{
+			[]BlockedPort{{Ports: "abc"}, {Ports: "4000"}, {Ports: " 4001 "}},
+			[]BlockedPort{{Ports: "4000-4001"}, {Ports: "abc"}},
+			"unparsable specs are kept",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			reg := createTestRegistry(t)
+			reg.blockedPorts = tt.blocked
+
+			require.NoError(t, reg.NormalizeBlockedPorts())
+			assert.Equal(t, tt.normalized, reg.blockedPorts)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package registry
+
+// noteworthyPorts are ports that are not blocked by default but are commonly
+// used by development servers, so an auto-assigned one may collide with a tool
+// started without portreg
+var noteworthyPorts = map[int]string{
+	3000: "Rails, Node.js, and React development servers",
+	4000: "Phoenix and Jekyll development servers",
+	4200: "Angular development server",
+	5000: "Flask development server and macOS AirPlay Receiver",
+	5173: "Vite development server",
+	8000: "Django and Python http.server",
+	8081: "React Native Metro bundler",
+	8443: "HTTPS alternative port",
+	8888: "Jupyter Notebook",
+	9000: "PHP-FPM and SonarQube",
+}
+
+// NoteworthyPort reports whether port is commonly used by development tools
+// even though it is not blocked, and returns what it is known for
+func NoteworthyPort(port int) (string, bool) {
+	usedBy, ok := noteworthyPorts[port]
+	return usedBy, ok
+}
@@ -0,0 +1,23 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteworthyPort(t *testing.T) {
+	usedBy, ok := NoteworthyPort(5000)
+	assert.True(t, ok)
+	assert.Contains(t, usedBy, "Flask")
+
+	_, ok = NoteworthyPort(3100)
+	assert.False(t, ok)
+
+	// Noteworthy ports are a softer signal than the default blocked ports
+	for _, bp := range DefaultBlockedPorts() {
+		for port := range noteworthyPorts {
+			assert.False(t, isPortInRange(port, bp.Ports), "port %d is already blocked", port)
+		}
+	}
+}
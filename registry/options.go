@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"log/slog"
+	"os"
+)
+
+// Option configures a Registry created by New. Options only affect the
+// instance; none of them are saved to the registry file.
+type Option func(*Registry)
+
+// WithStartPort sets the port auto-assignment starts from, overriding the
+// registry file. See SetStartPort.
+func WithStartPort(port int) Option {
+	return func(r *Registry) {
+		r.SetStartPort(port)
+	}
+}
+
+// WithEndPort sets the last port auto-assignment may use, overriding the
+// registry file. See SetEndPort.
+func WithEndPort(port int) Option {
+	return func(r *Registry) {
+		r.SetEndPort(port)
+	}
+}
+
+// WithVerify makes assignment refuse ports that cannot currently be bound. See
+// SetVerify.
+func WithVerify(verify bool) Option {
+	return func(r *Registry) {
+		r.SetVerify(verify)
+	}
+}
+
+// WithReadOnly makes Save fail with ErrReadOnly. See SetReadOnly.
+func WithReadOnly(readOnly bool) Option {
+	return func(r *Registry) {
+		r.SetReadOnly(readOnly)
+	}
+}
+
+// WithLenientLoad loads registry files with assignments without a port or
+// blocked entries without ports instead of failing with ErrMalformedRegistry,
+// so they can be removed with Dedupe.
+func WithLenientLoad(lenient bool) Option {
+	return func(r *Registry) {
+		r.lenient = lenient
+	}
+}
+
+// WithFileMode sets the permission mode the registry file is written with. See
+// SetFileMode.
+func WithFileMode(mode os.FileMode) Option {
+	return func(r *Registry) {
+		r.SetFileMode(mode)
+	}
+}
+
+// WithLogger sets the logger that receives debug logs. See SetLogger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Registry) {
+		r.SetLogger(logger)
+	}
+}
+
+// WithAuditLog enables the audit log and writes it to path. See SetAuditLog.
+func WithAuditLog(path string) Option {
+	return func(r *Registry) {
+		r.SetAuditLog(path)
+	}
+}
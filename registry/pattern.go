@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Blocked specs may be patterns as well as ports and ranges:
+//
+//   - "30xx" matches the ports with the digits 30 followed by exactly two more
+//     digits (3000-3099). Each trailing x (or X) stands for one digit.
+//   - "3*" matches every port whose decimal form starts with 3 (3, 30-39,
+//     300-399, 3000-3999, and 30000-39999).
+//
+// The digits before the wildcard must be non-empty and must not start with 0.
+//
+// Several ports, ranges, and patterns may be combined in one spec separated by
+// commas, e.g. "3000-3010, 4000, 50xx".
+
+// isPortPattern reports whether spec is a pattern rather than a port or range
+func isPortPattern(spec string) bool {
+	return strings.ContainsAny(spec, "xX*")
+}
+
+// parseBlockedSpec returns the port ranges a blocked spec covers. A spec may
+// list several ports, ranges, or patterns separated by commas. Port and range
+// specs are not checked against the valid port numbers; use
+// validateBlockedSpec for that.
+func parseBlockedSpec(spec string) ([]blockedRange, error) {
+	return parseSpecComponents(spec, func(component string) ([]blockedRange, error) {
+		if isPortPattern(component) {
+			return parsePortPattern(component)
+		}
+
+		start, end, err := parsePortRange(component)
+		if err != nil {
+			return nil, err
+		}
+		if start > end {
+			return nil, fmt.Errorf("%w: %s (start is greater than end)", ErrInvalidPortRange, component)
+		}
+		return []blockedRange{{start, end}}, nil
+	})
+}
+
+// validateBlockedSpec parses a blocked spec and checks that it only contains
+// valid port numbers
+func validateBlockedSpec(spec string) ([]blockedRange, error) {
+	return parseSpecComponents(spec, func(component string) ([]blockedRange, error) {
+		if isPortPattern(component) {
+			return parsePortPattern(component)
+		}
+
+		start, end, err := validatePortRange(component)
+		if err != nil {
+			return nil, err
+		}
+		return []blockedRange{{start, end}}, nil
+	})
+}
+
+// parseSpecComponents splits spec on commas and returns the ranges parse
+// returns for each component, in the order they are listed. Surrounding
+// whitespace is ignored; empty components are an error.
+func parseSpecComponents(spec string, parse func(string) ([]blockedRange, error)) ([]blockedRange, error) {
+	if !strings.Contains(spec, ",") {
+		return parse(spec)
+	}
+
+	var ranges []blockedRange
+	for component := range strings.SplitSeq(spec, ",") {
+		component = strings.TrimSpace(component)
+		if component == "" {
+			return nil, fmt.Errorf("%w: %s (empty component)", ErrInvalidPortRange, spec)
+		}
+		r, err := parse(component)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r...)
+	}
+	return ranges, nil
+}
+
+// parsePortPattern returns the ranges of valid ports matched by a pattern spec
+// such as "30xx" or "3*", in ascending order
+func parsePortPattern(spec string) ([]blockedRange, error) {
+	pattern := strings.TrimSpace(spec)
+
+	var prefix string
+	var wildcardDigits []int // numbers of digits that may follow prefix
+	if p, ok := strings.CutSuffix(pattern, "*"); ok {
+		prefix = p
+		for n := 0; len(prefix)+n <= len(strconv.Itoa(maxPort)); n++ {
+			wildcardDigits = append(wildcardDigits, n)
+		}
+	} else {
+		prefix = strings.TrimRight(pattern, "xX")
+		wildcardDigits = []int{len(pattern) - len(prefix)}
+	}
+
+	if prefix == "" || prefix[0] == '0' || strings.ContainsFunc(prefix, func(c rune) bool { return c < '0' || c > '9' }) {
+		return nil, fmt.Errorf("%w: %s (patterns are digits followed by x's or *)", ErrInvalidPortRange, spec)
+	}
+	base, err := strconv.Atoi(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPortRange, spec)
+	}
+
+	var ranges []blockedRange
+	for _, n := range wildcardDigits {
+		scale := 1
+		for range n {
+			scale *= 10
+		}
+		start := base * scale
+		if start > maxPort {
+			break
+		}
+		ranges = append(ranges, blockedRange{start, min(start+scale-1, maxPort)})
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("%w: %s (ports must be between %d and %d)", ErrInvalidPortRange, spec, minPort, maxPort)
+	}
+	return ranges, nil
+}
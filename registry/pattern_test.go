@@ -0,0 +1,61 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlockedSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []blockedRange
+	}{
+		{"8000", []blockedRange{{8000, 8000}}},
+		{"3000-3010", []blockedRange{{3000, 3010}}},
+		{"30xx", []blockedRange{{3000, 3099}}},
+		{"30XX", []blockedRange{{3000, 3099}}},
+		{"3xxx", []blockedRange{{3000, 3999}}},
+		{"655xx", []blockedRange{{65500, 65535}}},
+		{"3*", []blockedRange{{3, 3}, {30, 39}, {300, 399}, {3000, 3999}, {30000, 39999}}},
+		{"65*", []blockedRange{{65, 65}, {650, 659}, {6500, 6599}, {65000, 65535}}},
+		{"8080*", []blockedRange{{8080, 8080}}},
+		{"3000-3010,4000", []blockedRange{{3000, 3010}, {4000, 4000}}},
+		{"3000-3010, 40xx", []blockedRange{{3000, 3010}, {4000, 4099}}},
+	}
+	for _, tt := range tests {
+		got, err := validateBlockedSpec(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.want, got, tt.spec)
+	}
+
+	for _, spec := range []string{"xx", "*", "0xx", "3x0x", "30xx*", "7xxxx", "3-5x", "abc*", "3000,", "3000,70000", "3000, 5-3"} {
+		_, err := validateBlockedSpec(spec)
+		assert.ErrorIs(t, err, ErrInvalidPortRange, spec)
+	}
+}
+
+func TestBlockedPattern(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.SetStartPort(3000)
+	require.NoError(t, reg.BlockPorts("30xx", "pattern"))
+
+	assert.False(t, reg.IsPortAvailable(3000))
+	assert.False(t, reg.IsPortAvailable(3099))
+	assert.True(t, reg.IsPortAvailable(2999))
+	assert.True(t, reg.IsPortAvailable(3100))
+
+	port, err := reg.NextAvailable()
+	require.NoError(t, err)
+	assert.Equal(t, 3100, port)
+
+	require.NoError(t, reg.AssignPort(4000, "project", ""))
+	err = reg.BlockPorts("4*", "")
+	assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+	require.NoError(t, reg.BlockPorts("5*", ""))
+	assert.False(t, reg.IsPortAvailable(50000))
+	assert.True(t, reg.IsPortAvailable(6000))
+
+	assert.Empty(t, reg.ValidateBlockedPorts())
+}
@@ -1,32 +1,112 @@
+// Package registry reads and writes a portreg registry file: the ports
+// assigned to projects and the ports that are blocked from assignment. It is
+// the library behind the portreg command and can be embedded in other tools.
 package registry
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Assignment represents a port assignment to a project
 type Assignment struct {
-	Port        int    `json:"port"`
-	Description string `json:"description,omitempty"`
-	Path        string `json:"path,omitempty"`
+	Port        int      `json:"port" yaml:"port"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Path        string   `json:"path,omitempty" yaml:"path,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Notes       string   `json:"notes,omitempty" yaml:"notes,omitempty"`
+
+	// Name distinguishes the ports of one project (e.g. web, grpc). It is unique
+	// per path.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Label is a color name (e.g. red) used to highlight the port in list
+	// output. It is cosmetic; unknown labels are stored but not rendered.
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+
+	// Reserved marks a port held for future use that is not tied to a project yet
+	Reserved bool `json:"reserved,omitempty" yaml:"reserved,omitempty"`
+
+	// Protocol is ProtocolTCP or ProtocolUDP. Empty means TCP. A port may be
+	// assigned once per protocol.
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+
+	// CreatedAt is when the port was assigned. It is zero for assignments made
+	// before creation times were recorded.
+	CreatedAt time.Time `json:"createdAt,omitzero" yaml:"createdAt,omitempty"`
+
+	// ExpiresAt is when the assignment expires and may be pruned. Zero means it
+	// never expires.
+	ExpiresAt time.Time `json:"expiresAt,omitzero" yaml:"expiresAt,omitempty"`
+}
+
+// Expired reports whether the assignment has an expiry that is not after now
+func (a Assignment) Expired(now time.Time) bool {
+	return !a.ExpiresAt.IsZero() && !a.ExpiresAt.After(now)
+}
+
+// Protocols an assignment may be annotated with
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// EffectiveProtocol returns the protocol of a, defaulting to ProtocolTCP
+func (a Assignment) EffectiveProtocol() string {
+	if a.Protocol == "" {
+		return ProtocolTCP
+	}
+	return a.Protocol
 }
 
 // BlockedPort represents a port or range of ports that should not be assigned
 type BlockedPort struct {
-	Ports       string `json:"ports"`
-	Description string `json:"description,omitempty"`
+	Ports       string `json:"ports" yaml:"ports"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 }
 
-// registryData represents the JSON structure of the registry file
+// DefaultStartPort is the first port considered for auto-assignment when none is configured
+const DefaultStartPort = 3100
+
+// DefaultFileMode is the permission mode the registry file is created with
+// when none is set. The umask applies to it.
+const DefaultFileMode os.FileMode = 0644
+
+// minPort and maxPort are the lowest and highest valid port numbers
+const (
+	minPort = 1
+	maxPort = 65535
+)
+
+// DefaultEndPort is the last port considered for auto-assignment when none is configured
+const DefaultEndPort = maxPort
+
+// registryData represents the JSON (or YAML) structure of the registry file
 type registryData struct {
-	Assignments  []Assignment  `json:"assignments"`
-	BlockedPorts []BlockedPort `json:"blockedPorts"`
+	Version          int           `json:"version" yaml:"version"`
+	StartPort        int           `json:"startPort,omitempty" yaml:"startPort,omitempty"`
+	EndPort          int           `json:"endPort,omitempty" yaml:"endPort,omitempty"`
+	ProtectAutoRange bool          `json:"protectAutoRange,omitempty" yaml:"protectAutoRange,omitempty"`
+	Compact          bool          `json:"compact,omitempty" yaml:"compact,omitempty"`
+	Includes         []string      `json:"includes,omitempty" yaml:"includes,omitempty"`
+	OnChange         string        `json:"onChange,omitempty" yaml:"onChange,omitempty"`
+	Audit            bool          `json:"audit,omitempty" yaml:"audit,omitempty"`
+	Assignments      []Assignment  `json:"assignments" yaml:"assignments"`
+	BlockedPorts     []BlockedPort `json:"blockedPorts" yaml:"blockedPorts"`
+	AllowedPorts     []AllowedPort `json:"allowedPorts,omitempty" yaml:"allowedPorts,omitempty"`
 }
 
 // Registry manages port assignments and persistence
@@ -34,6 +114,96 @@ type Registry struct {
 	path         string
 	assignments  []Assignment
 	blockedPorts []BlockedPort
+
+	// allowedPorts restricts assignment to its ranges when not empty
+	allowedPorts []AllowedPort
+
+	// startPort is the auto-assignment start port stored in the registry file
+	startPort int
+
+	// startPortOverride takes precedence over startPort and is never saved
+	startPortOverride int
+
+	// endPort is the auto-assignment end port stored in the registry file
+	endPort int
+
+	// endPortOverride takes precedence over endPort and is never saved
+	endPortOverride int
+
+	// protectAutoRange is stored in the registry file and makes Assign refuse
+	// specific ports inside the auto-assignment window
+	protectAutoRange bool
+
+	// allowAutoRange overrides protectAutoRange and is never saved
+	allowAutoRange bool
+
+	// compact is stored in the registry file and makes Save write JSON without
+	// indentation
+	compact bool
+
+	// includes lists the registry files whose assignments are merged read-only.
+	// It is stored in the registry file.
+	includes []string
+
+	// included holds the assignments read from includes. It is never saved.
+	included []IncludedAssignment
+
+	// onChange is the command stored in the registry file that the portreg
+	// command runs after assigning or unassigning ports
+	onChange string
+
+	// audit is stored in the registry file and makes saved changes append to
+	// the audit log
+	audit bool
+
+	// auditPath overrides the audit log path and enables the audit log. It is
+	// never saved.
+	auditPath string
+
+	// pendingAudit holds audit entries of changes made in dry run mode
+	pendingAudit []AuditEntry
+
+	// verify makes assignment check that the OS can bind the port
+	verify bool
+
+	// dryRun makes Save a no-op so changes are only made in memory
+	dryRun bool
+
+	// lenient skips the schema validation of loaded files so Dedupe can remove
+	// the malformed entries it would reject
+	lenient bool
+
+	// readOnly makes Save fail with ErrReadOnly
+	readOnly bool
+
+	// fileMode is the permission mode Save writes the registry file with
+	fileMode os.FileMode
+
+	// fileModeSet reports whether fileMode was set with SetFileMode. Only then
+	// is it applied exactly; otherwise the umask applies to it.
+	fileModeSet bool
+
+	// rename moves the temporary file over the registry file. It is
+	// replaceable for tests.
+	rename func(oldpath, newpath string) error
+
+	// now returns the current time. It is replaceable for tests.
+	now func() time.Time
+
+	// logger receives debug logs of loading, saving, and port selection. It
+	// discards them unless set with SetLogger or WithLogger.
+	logger *slog.Logger
+
+	// lockFile is the open lock file while the lock is held
+	lockFile *os.File
+
+	// fileInfo describes the registry file as of the last load or save. It is
+	// nil if the file did not exist.
+	fileInfo os.FileInfo
+
+	// contentHash is the SHA-256 of the registry file as of the last load or
+	// save. It is only meaningful when fileInfo is set.
+	contentHash [sha256.Size]byte
 }
 
 // Custom errors
@@ -43,14 +213,50 @@ var (
 	ErrPortBlocked         = errors.New("port is in blocked range")
 	ErrNoPortsAvailable    = errors.New("no available ports found")
 	ErrInvalidPortRange    = errors.New("invalid port range")
+	ErrInvalidPort         = errors.New("invalid port")
+	ErrRegistryModified    = errors.New("registry file was modified since it was loaded")
+	ErrPortInUse           = errors.New("port is in use")
+	ErrPortsNotBlocked     = errors.New("ports are not blocked")
+	ErrReadOnly            = errors.New("registry is in read-only mode")
+	ErrPortInAutoRange     = errors.New("port is in the auto-assignment range")
+	ErrNameInUse           = errors.New("name is already used for this path")
+	ErrMalformedRegistry   = errors.New("malformed registry file")
+	ErrInvalidProtocol     = errors.New("invalid protocol")
+	ErrAmbiguousProtocol   = errors.New("port is assigned for more than one protocol")
+	ErrUnsupportedVersion  = errors.New("unsupported registry file version")
+	ErrCorruptRegistry     = errors.New("registry file is corrupt")
 )
 
-// New creates a new Registry instance, loading from file if it exists
-func New(path string) (*Registry, error) {
+// PortConflictError is returned when a port cannot be assigned because it is
+// already assigned. It wraps ErrPortAlreadyAssigned.
+type PortConflictError struct {
+	// Assignment is the existing assignment of the port
+	Assignment Assignment
+}
+
+func (e *PortConflictError) Error() string {
+	return fmt.Sprintf("%v: port %d is already assigned to '%s'", ErrPortAlreadyAssigned, e.Assignment.Port, e.Assignment.Description)
+}
+
+func (e *PortConflictError) Unwrap() error {
+	return ErrPortAlreadyAssigned
+}
+
+// New creates a new Registry instance, loading from file if it exists. Options
+// configure the instance, e.g. New(path, WithStartPort(8000)).
+func New(path string, opts ...Option) (*Registry, error) {
 	r := &Registry{
 		path:         path,
 		assignments:  []Assignment{},
 		blockedPorts: []BlockedPort{},
+		now:          time.Now,
+		fileMode:     DefaultFileMode,
+		rename:       os.Rename,
+		logger:       discardLogger,
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
 
 	// Load existing registry if file exists
@@ -58,27 +264,83 @@ func New(path string) (*Registry, error) {
 		if err := r.load(); err != nil {
 			return nil, fmt.Errorf("failed to load registry: %w", err)
 		}
+	} else {
+		r.log().Debug("registry file does not exist", "path", path)
 	}
 
 	return r, nil
 }
 
-// Init initializes a new registry file with default blocked ports
-func (r *Registry) Init() error {
-	// Check if file already exists
-	if _, err := os.Stat(r.path); err == nil {
-		return fmt.Errorf("registry file already exists at %s", r.path)
+// NewFromData returns a read-only Registry parsed from the contents of a
+// registry file that is not on disk, such as one fetched from a server. name
+// identifies the source in errors and logs, and an extension of .yaml or .yml
+// selects YAML as for New. Included files cannot be resolved without a
+// directory, so a registry with includes is an error. Save always fails with
+// ErrReadOnly.
+func NewFromData(name string, data []byte, opts ...Option) (*Registry, error) {
+	r := &Registry{
+		path:         name,
+		assignments:  []Assignment{},
+		blockedPorts: []BlockedPort{},
+		now:          time.Now,
+		fileMode:     DefaultFileMode,
+		rename:       os.Rename,
+		logger:       discardLogger,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.readOnly = true
+
+	regData, err := r.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry: %w", err)
 	}
+	if len(regData.Includes) > 0 {
+		return nil, fmt.Errorf("failed to load registry: %s includes other registry files, which is only supported for files on disk", name)
+	}
+
+	r.setData(regData, nil)
+	r.log().Debug("loaded registry", "path", r.path, "assignments", len(r.assignments), "blockedPorts", len(r.blockedPorts), "allowedPorts", len(r.allowedPorts))
+	return r, nil
+}
 
-	// Set default blocked ports for common services
-	r.blockedPorts = []BlockedPort{
+// DefaultBlockedPorts returns the blocked ports for common services that Init
+// seeds a new registry with
+func DefaultBlockedPorts() []BlockedPort {
+	return []BlockedPort{
 		{Ports: "3306", Description: "MySQL default port"},
 		{Ports: "5432", Description: "PostgreSQL default port"},
 		{Ports: "6379", Description: "Redis default port"},
 		{Ports: "8080", Description: "Common HTTP alternative port"},
 		{Ports: "27017", Description: "MongoDB default port"},
 	}
+}
+
+// Init initializes a new registry file with default blocked ports
+func (r *Registry) Init() error {
+	return r.InitWith(DefaultBlockedPorts())
+}
+
+// InitWith initializes a new registry file with the given blocked ports, which
+// may be empty
+func (r *Registry) InitWith(blocked []BlockedPort) error {
+	// Check if file already exists
+	if _, err := os.Stat(r.path); err == nil {
+		return fmt.Errorf("registry file already exists at %s", r.path)
+	}
 
+	for _, bp := range blocked {
+		if _, err := validateBlockedSpec(bp.Ports); err != nil {
+			return err
+		}
+	}
+
+	r.blockedPorts = slices.Clone(blocked)
+	if r.blockedPorts == nil {
+		r.blockedPorts = []BlockedPort{}
+	}
 	r.assignments = []Assignment{}
 
 	return r.Save()
@@ -86,11 +348,66 @@ func (r *Registry) Init() error {
 
 // AssignPort assigns a specific port to a project
 func (r *Registry) AssignPort(port int, description, path string) error {
+	return r.Assign(Assignment{
+		Port:        port,
+		Description: description,
+		Path:        path,
+	})
+}
+
+// Assign adds an assignment for the port in a. If the registry protects the
+// auto-assignment range, ports inside it are refused with ErrPortInAutoRange.
+func (r *Registry) Assign(a Assignment) error {
+	if err := r.checkAutoRange(a.Port); err != nil {
+		return err
+	}
+
+	return r.assign(a)
+}
+
+// assign adds an assignment for the port in a without checking the
+// auto-assignment range policy
+func (r *Registry) assign(a Assignment) error {
+	if err := validateProtocol(a.Protocol); err != nil {
+		return err
+	}
+
+	if err := r.checkAssignable(a.Port, a.EffectiveProtocol()); err != nil {
+		r.log().Debug("refused port", "port", a.Port, "protocol", a.EffectiveProtocol(), "reason", err)
+		return err
+	}
+
+	a.Path = canonicalPath(a.Path)
+	if err := r.checkName(a); err != nil {
+		return err
+	}
+
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = r.createdAt()
+	}
+
+	// Add assignment
+	r.assignments = append(r.assignments, a)
+
+	if err := r.Save(); err != nil {
+		return err
+	}
+	r.recordAudit(auditEntries(AuditAssign, []Assignment{a})...)
+	return nil
+}
+
+// checkAssignable returns an error if a port cannot be assigned for protocol
+func (r *Registry) checkAssignable(port int, protocol string) error {
+	if err := validatePort(port); err != nil {
+		return err
+	}
+
 	// Check if port is already assigned
-	for _, a := range r.assignments {
-		if a.Port == port {
-			return fmt.Errorf("%w: port %d is already assigned to '%s'", ErrPortAlreadyAssigned, port, a.Description)
-		}
+	if i := r.protocolAssignmentIndex(port, protocol); i != -1 {
+		return &PortConflictError{Assignment: r.assignments[i]}
+	}
+	if ia, ok := r.includedAssignment(port, protocol); ok {
+		return &PortConflictError{Assignment: ia.Assignment}
 	}
 
 	// Check if port is blocked
@@ -98,168 +415,1502 @@ func (r *Registry) AssignPort(port int, description, path string) error {
 		return fmt.Errorf("%w: port %d", ErrPortBlocked, port)
 	}
 
-	// Add assignment
-	r.assignments = append(r.assignments, Assignment{
+	if !r.isPortAllowed(port) {
+		return fmt.Errorf("%w: port %d", ErrPortNotAllowed, port)
+	}
+
+	// Check if port is in use by another process
+	if r.verify && !CheckBindable(port, protocol) {
+		return fmt.Errorf("%w: port %d could not be bound", ErrPortInUse, port)
+	}
+
+	return nil
+}
+
+// checkName returns ErrNameInUse if another port assigned to the path of a
+// has the same name. Names only need to be unique per path.
+func (r *Registry) checkName(a Assignment) error {
+	if a.Name == "" || a.Path == "" {
+		return nil
+	}
+
+	for _, b := range r.FindByPath(a.Path) {
+		if b.Port != a.Port && b.Name == a.Name {
+			return fmt.Errorf("%w: %s is already port %d for %s", ErrNameInUse, a.Name, b.Port, a.Path)
+		}
+	}
+
+	return nil
+}
+
+// checkAutoRange returns ErrPortInAutoRange if the auto-assignment range is
+// protected, not overridden with SetAllowAutoRange, and contains port
+func (r *Registry) checkAutoRange(port int) error {
+	if !r.protectAutoRange || r.allowAutoRange {
+		return nil
+	}
+
+	start, end := r.StartPort(), r.EndPort()
+	if port >= start && port <= end {
+		return fmt.Errorf("%w: port %d is between %d and %d", ErrPortInAutoRange, port, start, end)
+	}
+
+	return nil
+}
+
+// Reserve holds a port for future use without tying it to a project. A
+// reserved port is unavailable like any other assigned port.
+func (r *Registry) Reserve(port int, description string) error {
+	return r.Assign(Assignment{
 		Port:        port,
 		Description: description,
-		Path:        path,
+		Reserved:    true,
 	})
+}
 
-	return r.Save()
+// NextAvailable returns the port AssignNextAvailable would assign without assigning it
+func (r *Registry) NextAvailable() (int, error) {
+	return r.nextAvailable(ProtocolTCP)
 }
 
-// AssignNextAvailable finds and assigns the next available port
-func (r *Registry) AssignNextAvailable(description, path string) (int, error) {
-	port := r.findNextAvailablePort()
+// nextAvailable returns the next available port for protocol
+func (r *Registry) nextAvailable(protocol string) (int, error) {
+	port := r.findNextAvailablePort(protocol)
 	if port == -1 {
 		return 0, ErrNoPortsAvailable
 	}
+	return port, nil
+}
+
+// AssignNextAvailable finds and assigns the next available port
+func (r *Registry) AssignNextAvailable(description, path string) (int, error) {
+	return r.AssignNext(Assignment{
+		Description: description,
+		Path:        path,
+	})
+}
+
+// AssignNext finds the next available port and adds a as its assignment. The
+// Port of a is ignored.
+func (r *Registry) AssignNext(a Assignment) (int, error) {
+	port, err := r.nextAvailable(a.EffectiveProtocol())
+	if err != nil {
+		return 0, err
+	}
 
-	if err := r.AssignPort(port, description, path); err != nil {
+	a.Port = port
+	if err := r.assign(a); err != nil {
 		return 0, err
 	}
 
 	return port, nil
 }
 
-// UnassignPort releases a port assignment
-func (r *Registry) UnassignPort(port int) error {
-	found := false
-	newAssignments := []Assignment{}
+// EnsureAssignment returns the port assigned to the path of a, or if there is
+// none, auto-assigns the next available port to a like AssignNext. If a has a
+// Name, only a port with that name counts as existing. The lowest matching port
+// is returned when there are several. created reports whether a port was
+// assigned. Running it again with the same a returns the same port.
+func (r *Registry) EnsureAssignment(a Assignment) (port int, created bool, err error) {
+	if a.Path == "" {
+		return 0, false, errors.New("path is required")
+	}
 
-	for _, a := range r.assignments {
-		if a.Port == port {
-			found = true
-		} else {
-			newAssignments = append(newAssignments, a)
+	port = -1
+	for _, b := range r.FindByPath(a.Path) {
+		if a.Name != "" && b.Name != a.Name {
+			continue
+		}
+		if port == -1 || b.Port < port {
+			port = b.Port
 		}
 	}
-
-	if !found {
-		return fmt.Errorf("%w: port %d", ErrPortNotAssigned, port)
+	if port != -1 {
+		r.log().Debug("found existing assignment", "port", port, "path", a.Path)
+		return port, false, nil
 	}
 
-	r.assignments = newAssignments
-	return r.Save()
+	port, err = r.AssignNext(a)
+	if err != nil {
+		return 0, false, err
+	}
+	return port, true, nil
 }
 
-// ListAssignments returns all current port assignments
-func (r *Registry) ListAssignments() []Assignment {
-	return r.assignments
+// AssignNextInRange finds and assigns the lowest available port from start to
+// end inclusive, regardless of the auto-assignment window. It returns
+// ErrNoPortsAvailable if every port in the range is taken.
+func (r *Registry) AssignNextInRange(start, end int, description, path string) (int, error) {
+	return r.AssignNextIn(start, end, Assignment{
+		Description: description,
+		Path:        path,
+	})
 }
 
-// IsPortAvailable checks if a port can be assigned
-func (r *Registry) IsPortAvailable(port int) bool {
-	// Check assignments
-	for _, a := range r.assignments {
-		if a.Port == port {
-			return false
-		}
+// AssignNextIn finds the lowest available port from start to end inclusive and
+// adds a as its assignment. The Port of a is ignored.
+func (r *Registry) AssignNextIn(start, end int, a Assignment) (int, error) {
+	if validatePort(start) != nil || validatePort(end) != nil || start > end {
+		return 0, fmt.Errorf("%w: %d-%d", ErrInvalidPortRange, start, end)
+	}
+
+	port := r.findAvailablePortIn(start, end, a.EffectiveProtocol())
+	if port == -1 {
+		return 0, fmt.Errorf("%w: between %d and %d", ErrNoPortsAvailable, start, end)
+	}
+
+	a.Port = port
+	if err := r.assign(a); err != nil {
+		return 0, err
 	}
 
-	// Check blocked ports
-	return !r.isPortBlocked(port)
+	return port, nil
 }
 
-// Save persists the registry to disk
-func (r *Registry) Save() error {
-	data := registryData{
-		Assignments:  r.assignments,
-		BlockedPorts: r.blockedPorts,
+// AssignBlock finds and assigns the first run of count consecutive available ports
+func (r *Registry) AssignBlock(count int, description, path string) ([]int, error) {
+	return r.AssignNextBlock(count, Assignment{
+		Description: description,
+		Path:        path,
+	})
+}
+
+// AssignNextBlock finds the first run of count consecutive available ports and
+// adds a copy of a as the assignment of each. The Port of a is ignored.
+func (r *Registry) AssignNextBlock(count int, a Assignment) ([]int, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("invalid port count: %d", count)
 	}
 
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal registry: %w", err)
+	if a.Name != "" && count > 1 {
+		return nil, fmt.Errorf("name %s can only be given to a single port", a.Name)
+	}
+	if err := validateProtocol(a.Protocol); err != nil {
+		return nil, err
+	}
+	a.Path = canonicalPath(a.Path)
+	if err := r.checkName(a); err != nil {
+		return nil, err
 	}
 
-	// Ensure directory exists
-	dir := filepath.Dir(r.path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = r.createdAt()
 	}
 
-	// Write to temporary file first for atomic write
-	tmpFile := r.path + ".tmp"
-	if err := os.WriteFile(tmpFile, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write temporary file: %w", err)
+	start := r.findAvailableBlock(count, a.EffectiveProtocol())
+	if start == -1 {
+		return nil, ErrNoPortsAvailable
 	}
 
-	// Rename temporary file to actual file (atomic on most systems)
-	if err := os.Rename(tmpFile, r.path); err != nil {
-		os.Remove(tmpFile) // Clean up on error
-		return fmt.Errorf("failed to save registry: %w", err)
+	ports := make([]int, 0, count)
+	for port := start; port < start+count; port++ {
+		a.Port = port
+		r.assignments = append(r.assignments, a)
+		ports = append(ports, port)
 	}
 
-	return nil
+	if err := r.Save(); err != nil {
+		r.assignments = r.assignments[:len(r.assignments)-count]
+		return nil, err
+	}
+	r.recordAudit(auditEntries(AuditAssign, r.assignments[len(r.assignments)-count:])...)
+
+	return ports, nil
 }
 
-// load reads the registry from disk
-func (r *Registry) load() error {
-	data, err := os.ReadFile(r.path)
+// UnassignPort releases a port assignment. It fails with ErrAmbiguousProtocol
+// if the port is assigned for both TCP and UDP; use UnassignProtocol then.
+func (r *Registry) UnassignPort(port int) error {
+	return r.UnassignProtocol(port, "")
+}
+
+// UnassignProtocol releases the assignment of port for protocol. An empty
+// protocol matches the port's only assignment as in LookupAssignment.
+func (r *Registry) UnassignProtocol(port int, protocol string) error {
+	i, err := r.lookupIndex(port, protocol)
 	if err != nil {
-		return fmt.Errorf("failed to read registry file: %w", err)
+		return err
 	}
 
-	var regData registryData
-	if err := json.Unmarshal(data, &regData); err != nil {
-		return fmt.Errorf("failed to unmarshal registry: %w", err)
+	removed := r.assignments[i]
+	prev := r.assignments
+	r.assignments = append(r.assignments[:i:i], r.assignments[i+1:]...)
+	if err := r.Save(); err != nil {
+		r.assignments = prev
+		return err
 	}
+	r.recordAudit(auditEntries(AuditUnassign, []Assignment{removed})...)
+	return nil
+}
 
-	r.assignments = regData.Assignments
-	r.blockedPorts = regData.BlockedPorts
+// UnassignByPath releases every assignment for path and returns the number
+// removed. Paths are compared as in FindByPath.
+func (r *Registry) UnassignByPath(path string) (int, error) {
+	path = canonicalPath(path)
+	return r.unassignMatching(func(a Assignment) bool {
+		return a.Path != "" && canonicalPath(a.Path) == path
+	})
+}
 
-	return nil
+// UnassignByDescription releases every assignment whose description is exactly
+// description and returns the number removed
+func (r *Registry) UnassignByDescription(description string) (int, error) {
+	return r.unassignMatching(func(a Assignment) bool {
+		return a.Description == description
+	})
 }
 
-// isPortBlocked checks if a port is in any blocked range
-func (r *Registry) isPortBlocked(port int) bool {
-	for _, bp := range r.blockedPorts {
-		if isPortInRange(port, bp.Ports) {
-			return true
+// unassignMatching releases every assignment matched by match. Nothing is saved
+// if no assignment matches.
+func (r *Registry) unassignMatching(match func(Assignment) bool) (int, error) {
+	kept := []Assignment{}
+	removed := []Assignment{}
+	for _, a := range r.assignments {
+		if match(a) {
+			removed = append(removed, a)
+		} else {
+			kept = append(kept, a)
 		}
 	}
-	return false
+
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	prev := r.assignments
+	r.assignments = kept
+	if err := r.Save(); err != nil {
+		r.assignments = prev
+		return 0, err
+	}
+	r.recordAudit(auditEntries(AuditUnassign, removed)...)
+	return len(removed), nil
 }
 
-// findNextAvailablePort finds the lowest available port starting from 3100
-func (r *Registry) findNextAvailablePort() int {
-	startPort := 3100
-	maxPort := 65535
+// UpdateAssignment changes the description and path of an assigned port. It
+// fails with ErrAmbiguousProtocol if the port is assigned for both TCP and UDP;
+// use Update then.
+func (r *Registry) UpdateAssignment(port int, description, path string) error {
+	i, err := r.lookupIndex(port, "")
+	if err != nil {
+		return err
+	}
 
-	for port := startPort; port <= maxPort; port++ {
-		if r.IsPortAvailable(port) {
-			return port
-		}
+	prev := r.assignments[i]
+	r.assignments[i].Description = description
+	r.assignments[i].Path = canonicalPath(path)
+	if err := r.Save(); err != nil {
+		r.assignments[i] = prev
+		return err
 	}
+	return nil
+}
 
-	return -1
+// Update replaces the assignment for the port in a with a
+func (r *Registry) Update(a Assignment) error {
+	i := r.protocolAssignmentIndex(a.Port, a.EffectiveProtocol())
+	if i == -1 {
+		return fmt.Errorf("%w: port %d", ErrPortNotAssigned, a.Port)
+	}
+
+	a.Path = canonicalPath(a.Path)
+	if err := r.checkName(a); err != nil {
+		return err
+	}
+
+	prev := r.assignments[i]
+	r.assignments[i] = a
+	if err := r.Save(); err != nil {
+		r.assignments[i] = prev
+		return err
+	}
+	return nil
 }
 
-// isPortInRange checks if a port is within a range specification
-func isPortInRange(port int, rangeSpec string) bool {
-	// Check if it's a range (contains hyphen)
-	if strings.Contains(rangeSpec, "-") {
-		parts := strings.Split(rangeSpec, "-")
-		if len(parts) != 2 {
-			return false
-		}
+// Rename sets the description of every assignment whose description is
+// oldDescription to newDescription and returns the number changed. Nothing is
+// saved if no assignment matches.
+func (r *Registry) Rename(oldDescription, newDescription string) (int, error) {
+	return r.renameMatching(func(a Assignment) bool {
+		return a.Description == oldDescription
+	}, newDescription)
+}
 
-		start, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
-		end, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+// RenameByPath sets the description of every assignment for path to
+// newDescription and returns the number changed. Paths are compared as in
+// FindByPath.
+func (r *Registry) RenameByPath(path, newDescription string) (int, error) {
+	path = canonicalPath(path)
+	return r.renameMatching(func(a Assignment) bool {
+		return a.Path != "" && canonicalPath(a.Path) == path
+	}, newDescription)
+}
 
-		if err1 != nil || err2 != nil {
-			return false
+// renameMatching sets the description of every assignment matched by match
+func (r *Registry) renameMatching(match func(Assignment) bool, newDescription string) (int, error) {
+	prev := slices.Clone(r.assignments)
+	count := 0
+	for i := range r.assignments {
+		if match(r.assignments[i]) {
+			r.assignments[i].Description = newDescription
+			count++
 		}
+	}
 
-		return port >= start && port <= end
+	if count == 0 {
+		return 0, nil
 	}
 
-	// Single port
-	singlePort, err := strconv.Atoi(strings.TrimSpace(rangeSpec))
-	if err != nil {
+	if err := r.Save(); err != nil {
+		r.assignments = prev
+		return 0, err
+	}
+	return count, nil
+}
+
+// BlockPorts blocks a port, range of ports, or pattern such as "30xx". It fails
+// if any port it covers is already assigned.
+func (r *Registry) BlockPorts(spec, description string) error {
+	ranges, err := validateBlockedSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	if conflicts := r.assignmentsInRanges(ranges); len(conflicts) > 0 {
+		a := conflicts[0]
+		return fmt.Errorf("%w: port %d in %s is already assigned to '%s'", ErrPortAlreadyAssigned, a.Port, spec, a.Description)
+	}
+
+	r.blockedPorts = append(r.blockedPorts, BlockedPort{Ports: spec, Description: description})
+	return r.Save()
+}
+
+// AssignmentsInRange returns the assignments, sorted by port, with ports
+// covered by a port, range of ports, pattern, or comma-separated list of them.
+// It shows which assignments blocking spec would conflict with.
+func (r *Registry) AssignmentsInRange(spec string) ([]Assignment, error) {
+	ranges, err := validateBlockedSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return sortedAssignments(r.assignmentsInRanges(ranges)), nil
+}
+
+// ForceBlockPorts blocks a port, range of ports, or pattern even if some of the
+// ports are already assigned. It returns the assignments that it covers.
+func (r *Registry) ForceBlockPorts(spec, description string) ([]Assignment, error) {
+	ranges, err := validateBlockedSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := r.assignmentsInRanges(ranges)
+
+	r.blockedPorts = append(r.blockedPorts, BlockedPort{Ports: spec, Description: description})
+	if err := r.Save(); err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// UnblockPorts removes the blocked entry whose spec exactly matches spec
+func (r *Registry) UnblockPorts(spec string) error {
+	for i, bp := range r.blockedPorts {
+		if bp.Ports == spec {
+			r.blockedPorts = append(r.blockedPorts[:i:i], r.blockedPorts[i+1:]...)
+			return r.Save()
+		}
+	}
+
+	if len(r.blockedPorts) == 0 {
+		return fmt.Errorf("%w: %s (no ports are blocked)", ErrPortsNotBlocked, spec)
+	}
+
+	specs := make([]string, 0, len(r.blockedPorts))
+	for _, bp := range r.blockedPorts {
+		specs = append(specs, bp.Ports)
+	}
+	return fmt.Errorf("%w: %s (blocked: %s)", ErrPortsNotBlocked, spec, strings.Join(specs, ", "))
+}
+
+// MovePort moves an assignment from one port to another, preserving its other
+// fields. It fails with ErrAmbiguousProtocol if from is assigned for both TCP
+// and UDP; use MoveProtocol then.
+func (r *Registry) MovePort(from, to int) error {
+	return r.MoveProtocol(from, to, "")
+}
+
+// MoveProtocol moves the assignment of from for protocol to another port. An
+// empty protocol matches the port's only assignment as in LookupAssignment.
+func (r *Registry) MoveProtocol(from, to int, protocol string) error {
+	i, err := r.lookupIndex(from, protocol)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkAssignable(to, r.assignments[i].EffectiveProtocol()); err != nil {
+		return err
+	}
+
+	r.assignments[i].Port = to
+	if err := r.Save(); err != nil {
+		r.assignments[i].Port = from
+		return err
+	}
+
+	entry := auditEntries(AuditMove, r.assignments[i:i+1])[0]
+	entry.From = from
+	r.recordAudit(entry)
+	return nil
+}
+
+// ListAssignments returns all current port assignments. The returned slice is
+// a copy.
+func (r *Registry) ListAssignments() []Assignment {
+	return slices.Clone(r.assignments)
+}
+
+// GetAssignment returns the assignment for a port and whether it was found. If
+// the port is assigned for both TCP and UDP the TCP assignment is returned;
+// use LookupAssignment to tell them apart.
+func (r *Registry) GetAssignment(port int) (Assignment, bool) {
+	if i := r.protocolAssignmentIndex(port, ProtocolTCP); i != -1 {
+		return r.assignments[i], true
+	}
+	if i := r.protocolAssignmentIndex(port, ProtocolUDP); i != -1 {
+		return r.assignments[i], true
+	}
+	return Assignment{}, false
+}
+
+// LookupAssignment returns the assignment of port for protocol. An empty
+// protocol matches the port's only assignment and fails with
+// ErrAmbiguousProtocol if it is assigned for both TCP and UDP. It fails with
+// ErrPortNotAssigned if there is no match.
+func (r *Registry) LookupAssignment(port int, protocol string) (Assignment, error) {
+	i, err := r.lookupIndex(port, protocol)
+	if err != nil {
+		return Assignment{}, err
+	}
+	return r.assignments[i], nil
+}
+
+// lookupIndex returns the index of the assignment of port for protocol as
+// described in LookupAssignment
+func (r *Registry) lookupIndex(port int, protocol string) (int, error) {
+	if protocol != "" {
+		if err := validateProtocol(protocol); err != nil {
+			return -1, err
+		}
+		i := r.protocolAssignmentIndex(port, protocol)
+		if i == -1 {
+			return -1, fmt.Errorf("%w: port %d/%s", ErrPortNotAssigned, port, protocol)
+		}
+		return i, nil
+	}
+
+	found := -1
+	for i, a := range r.assignments {
+		if a.Port != port {
+			continue
+		}
+		if found != -1 {
+			return -1, fmt.Errorf("%w: port %d is assigned for both %s and %s", ErrAmbiguousProtocol, port, ProtocolTCP, ProtocolUDP)
+		}
+		found = i
+	}
+	if found == -1 {
+		return -1, fmt.Errorf("%w: port %d", ErrPortNotAssigned, port)
+	}
+	return found, nil
+}
+
+// protocolAssignmentIndex returns the index of the assignment for a port and
+// protocol or -1 if it is not assigned
+func (r *Registry) protocolAssignmentIndex(port int, protocol string) int {
+	for i, a := range r.assignments {
+		if a.Port == port && a.EffectiveProtocol() == protocol {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetBlockedPort returns the blocked entry that contains a port and whether one was found
+func (r *Registry) GetBlockedPort(port int) (BlockedPort, bool) {
+	for _, bp := range r.blockedPorts {
+		if isPortInRange(port, bp.Ports) {
+			return bp, true
+		}
+	}
+	return BlockedPort{}, false
+}
+
+// ListBlockedPorts returns all blocked ports. The returned slice is a copy.
+func (r *Registry) ListBlockedPorts() []BlockedPort {
+	return slices.Clone(r.blockedPorts)
+}
+
+// Search returns the assignments whose description or path contains query,
+// ignoring case
+func (r *Registry) Search(query string) []Assignment {
+	query = strings.ToLower(query)
+
+	matches := []Assignment{}
+	for _, a := range r.assignments {
+		if strings.Contains(strings.ToLower(a.Description), query) || strings.Contains(strings.ToLower(a.Path), query) {
+			matches = append(matches, a)
+		}
+	}
+	return matches
+}
+
+// FilterByTag returns the assignments that have tag
+func (r *Registry) FilterByTag(tag string) []Assignment {
+	matches := []Assignment{}
+	for _, a := range r.assignments {
+		if slices.Contains(a.Tags, tag) {
+			matches = append(matches, a)
+		}
+	}
+	return matches
+}
+
+// FilterByTime returns the assignments created at or after since and before
+// before. A zero since or before leaves that side unbounded. Assignments
+// without a creation time are never returned.
+func (r *Registry) FilterByTime(since, before time.Time) []Assignment {
+	matches := []Assignment{}
+	for _, a := range r.assignments {
+		if a.CreatedAt.IsZero() {
+			continue
+		}
+		if !since.IsZero() && a.CreatedAt.Before(since) {
+			continue
+		}
+		if !before.IsZero() && !a.CreatedAt.Before(before) {
+			continue
+		}
+		matches = append(matches, a)
+	}
+	return matches
+}
+
+// createdAt returns the creation time recorded for new assignments
+func (r *Registry) createdAt() time.Time {
+	return r.now().UTC().Truncate(time.Second)
+}
+
+// FindByPath returns the assignments for a project path, such as the named
+// ports of a project. Paths are compared in absolute, cleaned form, so
+// relative paths are resolved against the current directory.
+func (r *Registry) FindByPath(path string) []Assignment {
+	path = canonicalPath(path)
+
+	matches := []Assignment{}
+	for _, a := range r.assignments {
+		if a.Path != "" && canonicalPath(a.Path) == path {
+			matches = append(matches, a)
+		}
+	}
+	return matches
+}
+
+// FindByDescription returns the assignments whose description is exactly
+// description
+func (r *Registry) FindByDescription(description string) []Assignment {
+	matches := []Assignment{}
+	for _, a := range r.assignments {
+		if a.Description == description {
+			matches = append(matches, a)
+		}
+	}
+	return matches
+}
+
+// DescriptionInUse returns the ports, in order, of the assignments of other
+// projects whose description is exactly description. Assignments for path,
+// compared as in FindByPath, are ports of the same project and do not count.
+// The empty description is never in use.
+func (r *Registry) DescriptionInUse(description, path string) []int {
+	if description == "" {
+		return nil
+	}
+	if path != "" {
+		path = canonicalPath(path)
+	}
+
+	var ports []int
+	for _, a := range r.FindByDescription(description) {
+		if path != "" && a.Path != "" && canonicalPath(a.Path) == path {
+			continue
+		}
+		ports = append(ports, a.Port)
+	}
+	slices.Sort(ports)
+	return ports
+}
+
+// StalePaths returns the assignments whose path no longer exists. Assignments
+// without a path are never stale.
+func (r *Registry) StalePaths() []Assignment {
+	stale := []Assignment{}
+	for _, a := range r.assignments {
+		if isStalePath(a.Path) {
+			stale = append(stale, a)
+		}
+	}
+	return stale
+}
+
+// PruneStalePaths unassigns all assignments whose path no longer exists and
+// returns the removed assignments
+func (r *Registry) PruneStalePaths() ([]Assignment, error) {
+	removed := []Assignment{}
+	kept := []Assignment{}
+	for _, a := range r.assignments {
+		if isStalePath(a.Path) {
+			removed = append(removed, a)
+		} else {
+			kept = append(kept, a)
+		}
+	}
+
+	if len(removed) == 0 {
+		return removed, nil
+	}
+
+	r.assignments = kept
+	if err := r.Save(); err != nil {
+		return nil, err
+	}
+	r.recordAudit(auditEntries(AuditUnassign, removed)...)
+
+	return removed, nil
+}
+
+// ExpiredAssignments returns the assignments whose expiry has passed
+func (r *Registry) ExpiredAssignments() []Assignment {
+	now := r.now()
+	expired := []Assignment{}
+	for _, a := range r.assignments {
+		if a.Expired(now) {
+			expired = append(expired, a)
+		}
+	}
+	return expired
+}
+
+// PruneExpired unassigns all assignments whose expiry has passed and returns
+// the removed assignments. Assignments without an expiry are kept.
+func (r *Registry) PruneExpired() ([]Assignment, error) {
+	now := r.now()
+	removed := []Assignment{}
+	kept := []Assignment{}
+	for _, a := range r.assignments {
+		if a.Expired(now) {
+			removed = append(removed, a)
+		} else {
+			kept = append(kept, a)
+		}
+	}
+
+	if len(removed) == 0 {
+		return removed, nil
+	}
+
+	prev := r.assignments
+	r.assignments = kept
+	if err := r.Save(); err != nil {
+		r.assignments = prev
+		return nil, err
+	}
+	r.recordAudit(auditEntries(AuditUnassign, removed)...)
+
+	return removed, nil
+}
+
+// CanonicalizePaths rewrites every assignment path that is not in absolute,
+// cleaned form, resolving relative paths against the current directory, then
+// saves. It returns the assignments as they were before being changed. Nothing
+// is saved if every path is already canonical.
+func (r *Registry) CanonicalizePaths() ([]Assignment, error) {
+	changed := []Assignment{}
+	updated := slices.Clone(r.assignments)
+	for i, a := range updated {
+		if path := canonicalPath(a.Path); path != a.Path {
+			changed = append(changed, a)
+			updated[i].Path = path
+		}
+	}
+
+	if len(changed) == 0 {
+		return changed, nil
+	}
+
+	prev := r.assignments
+	r.assignments = updated
+	if err := r.Save(); err != nil {
+		r.assignments = prev
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// ValidateBlockedPorts checks every blocked entry and returns an error wrapping
+// ErrInvalidPortRange for each malformed spec. Malformed specs never block any
+// port.
+func (r *Registry) ValidateBlockedPorts() []error {
+	var errs []error
+	for i, bp := range r.blockedPorts {
+		if _, err := validateBlockedSpec(bp.Ports); err != nil {
+			errs = append(errs, fmt.Errorf("blocked entry %d: %w", i, err))
+		}
+	}
+	return errs
+}
+
+// IsPortAvailable checks if a port can be assigned. Use PortStatus to find out
+// why it cannot.
+func (r *Registry) IsPortAvailable(port int) bool {
+	return r.PortStatus(port).Available()
+}
+
+// Validate scans the whole registry for problems that loading tolerates, such
+// as a hand-edited file assigning the same port twice. It returns one error per
+// problem: invalid or duplicate assigned ports, assignments inside a blocked
+// range or outside the allowed ranges, names used twice for one path, ports
+// also assigned in an included file, malformed blocked specs, and malformed or
+// fully blocked allowed specs.
+func (r *Registry) Validate() []error {
+	var errs []error
+
+	type portKey struct {
+		port     int
+		protocol string
+	}
+	seen := make(map[portKey]Assignment, len(r.assignments))
+	for _, a := range r.assignments {
+		if err := validatePort(a.Port); err != nil {
+			errs = append(errs, fmt.Errorf("assignment '%s': %w", a.Description, err))
+			continue
+		}
+
+		if err := validateProtocol(a.Protocol); err != nil {
+			errs = append(errs, fmt.Errorf("assignment '%s': %w", a.Description, err))
+		}
+
+		key := portKey{a.Port, a.EffectiveProtocol()}
+		if prev, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("%w: port %d is assigned to both '%s' and '%s'", ErrPortAlreadyAssigned, a.Port, prev.Description, a.Description))
+		} else {
+			seen[key] = a
+		}
+
+		if bp, ok := r.GetBlockedPort(a.Port); ok {
+			errs = append(errs, fmt.Errorf("%w: port %d assigned to '%s' is in blocked range %s", ErrPortBlocked, a.Port, a.Description, bp.Ports))
+		}
+	}
+
+	for i, a := range r.assignments {
+		if a.Name == "" || a.Path == "" {
+			continue
+		}
+		for _, b := range r.assignments[:i] {
+			if b.Name == a.Name && b.Path != "" && canonicalPath(b.Path) == canonicalPath(a.Path) {
+				errs = append(errs, fmt.Errorf("%w: %s is both port %d and port %d for %s", ErrNameInUse, a.Name, b.Port, a.Port, a.Path))
+				break
+			}
+		}
+	}
+
+	errs = append(errs, r.validateIncludes()...)
+	errs = append(errs, r.ValidateBlockedPorts()...)
+	errs = append(errs, r.validateAllowedPorts()...)
+
+	return errs
+}
+
+// AvailableInRange returns every port from start to end inclusive that passes
+// IsPortAvailable. Ports outside the valid port range are ignored.
+func (r *Registry) AvailableInRange(start, end int) []int {
+	start = max(start, minPort)
+	end = min(end, maxPort)
+
+	av := r.newAvailability()
+	ports := []int{}
+	for port := start; port <= end; port++ {
+		if av.isAvailable(port) {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// SetStartPort overrides the port auto-assignment starts from for this Registry
+// instance. The override is not saved to the registry file. Zero clears the
+// override.
+func (r *Registry) SetStartPort(port int) {
+	r.startPortOverride = port
+}
+
+// StartPort returns the port auto-assignment starts from
+func (r *Registry) StartPort() int {
+	if r.startPortOverride > 0 {
+		return r.startPortOverride
+	}
+	if r.startPort > 0 {
+		return r.startPort
+	}
+	return DefaultStartPort
+}
+
+// Reload re-reads the registry from disk, discarding any in-memory state. If
+// the file does not exist the registry is reset to empty.
+func (r *Registry) Reload() error {
+	if _, err := os.Stat(r.path); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat registry file: %w", err)
+		}
+		r.startPort = 0
+		r.endPort = 0
+		r.protectAutoRange = false
+		r.compact = false
+		r.includes = nil
+		r.included = nil
+		r.onChange = ""
+		r.audit = false
+		r.assignments = []Assignment{}
+		r.blockedPorts = []BlockedPort{}
+		r.allowedPorts = nil
+		r.fileInfo = nil
+		r.contentHash = [sha256.Size]byte{}
+		return nil
+	}
+
+	return r.load()
+}
+
+// SetLogger sets the logger that receives debug logs of loading, saving, and
+// port selection. Nil discards them, which is the default.
+func (r *Registry) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = discardLogger
+	}
+	r.logger = logger
+}
+
+// discardLogger is the logger of a Registry without one set
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// log returns the logger of r
+func (r *Registry) log() *slog.Logger {
+	if r.logger == nil {
+		return discardLogger
+	}
+	return r.logger
+}
+
+// OnChange returns the hook command stored in the registry file, or "" if
+// none is set. The registry package never runs it; the portreg command runs it
+// after assignments change.
+func (r *Registry) OnChange() string {
+	return r.onChange
+}
+
+// SetEndPort overrides the last port auto-assignment may use for this Registry
+// instance. The override is not saved to the registry file. Zero clears the
+// override.
+func (r *Registry) SetEndPort(port int) {
+	r.endPortOverride = port
+}
+
+// EndPort returns the last port auto-assignment may use
+func (r *Registry) EndPort() int {
+	if r.endPortOverride > 0 {
+		return r.endPortOverride
+	}
+	if r.endPort > 0 {
+		return r.endPort
+	}
+	return DefaultEndPort
+}
+
+// SetVerify controls whether assignment checks that the OS can bind a port.
+// When enabled AssignPort fails with ErrPortInUse for ports held by another
+// process and auto-assignment skips them.
+func (r *Registry) SetVerify(verify bool) {
+	r.verify = verify
+}
+
+// SetDryRun controls whether changes are persisted. In dry-run mode all checks
+// are performed and changes are made in memory, but Save does not write the
+// registry file.
+func (r *Registry) SetDryRun(dryRun bool) {
+	r.dryRun = dryRun
+}
+
+// SetAllowAutoRange controls whether Assign may assign specific ports inside a
+// protected auto-assignment range. The setting is not saved.
+func (r *Registry) SetAllowAutoRange(allow bool) {
+	r.allowAutoRange = allow
+}
+
+// SetReadOnly controls whether the registry may be written. In read-only mode
+// Save returns ErrReadOnly instead of writing the registry file.
+func (r *Registry) SetReadOnly(readOnly bool) {
+	r.readOnly = readOnly
+}
+
+// SetFileMode sets the permission mode Save writes the registry file with.
+// The mode is not affected by the umask.
+func (r *Registry) SetFileMode(mode os.FileMode) {
+	r.fileMode = mode.Perm()
+	r.fileModeSet = true
+}
+
+// Save persists the registry to disk. It returns ErrRegistryModified if the
+// file was changed by someone else since it was loaded or last saved. Call
+// Reload and retry the change in that case. In dry-run mode Save does nothing.
+func (r *Registry) Save() error {
+	if r.dryRun {
+		r.log().Debug("dry run: not saving registry", "path", r.path)
+		return nil
+	}
+
+	if r.readOnly {
+		return ErrReadOnly
+	}
+
+	if err := r.checkUnmodified(); err != nil {
+		return err
+	}
+
+	data := registryData{
+		Version:          CurrentVersion,
+		StartPort:        r.startPort,
+		EndPort:          r.endPort,
+		ProtectAutoRange: r.protectAutoRange,
+		Compact:          r.compact,
+		Includes:         r.includes,
+		OnChange:         r.onChange,
+		Audit:            r.audit,
+		Assignments:      sortedAssignments(r.assignments),
+		BlockedPorts:     sortedBlockedPorts(r.blockedPorts),
+		AllowedPorts:     r.allowedPorts,
+	}
+
+	fileData, err := r.marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry: %w", err)
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to temporary file first for atomic write. It is created with the
+	// target mode so the contents are never more widely readable than the file.
+	tmpFile := r.path + ".tmp"
+	if err := writeFileMode(tmpFile, fileData, r.fileMode, r.fileModeSet); err != nil {
+		os.Remove(tmpFile) // Clean up on error
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// Keep the previous contents so a registry damaged later can be repaired
+	if err := r.writeBackup(); err != nil {
+		os.Remove(tmpFile) // Clean up on error
+		return err
+	}
+
+	// Rename temporary file to actual file (atomic on most systems)
+	if err := r.renameWithRetry(tmpFile, r.path); err != nil {
+		os.Remove(tmpFile) // Clean up on error
+		return fmt.Errorf("failed to save registry: %w", err)
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat registry file: %w", err)
+	}
+	r.fileInfo = info
+	r.contentHash = sha256.Sum256(fileData)
+
+	r.log().Debug("saved registry", "path", r.path, "assignments", len(r.assignments), "blockedPorts", len(r.blockedPorts))
+	return nil
+}
+
+// renameAttempts and renameBackoff bound the retries of renameWithRetry. The
+// backoff doubles after each failed attempt.
+const (
+	renameAttempts = 4
+	renameBackoff  = 10 * time.Millisecond
+)
+
+// renameWithRetry renames oldpath to newpath, retrying a few times with
+// backoff. On Windows the rename fails while another process such as an
+// antivirus scanner or editor briefly has the target open.
+func (r *Registry) renameWithRetry(oldpath, newpath string) error {
+	backoff := renameBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = r.rename(oldpath, newpath)
+		if err == nil || attempt == renameAttempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// writeFileMode writes data to a new file at name created with mode. If exact
+// is set the umask is overridden so the file gets exactly mode. A file left at
+// name by an earlier failed write is replaced rather than reused so it cannot
+// keep a wider mode.
+func writeFileMode(name string, data []byte, mode os.FileMode, exact bool) error {
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	// Chmod so the umask cannot narrow or change an explicitly requested mode
+	if exact {
+		if err := f.Chmod(mode); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// checkUnmodified returns ErrRegistryModified if the registry file changed
+// since it was loaded or last saved
+func (r *Registry) checkUnmodified() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat registry file: %w", err)
+	}
+
+	if r.fileInfo == nil || !fileUnchanged(r.fileInfo, info) {
+		return ErrRegistryModified
+	}
+
+	return nil
+}
+
+// SaveChecked is like Save, but also compares the contents of the registry
+// file with those last loaded or saved. This catches edits that keep the file's
+// size and modification time, which Save alone misses on file systems with
+// coarse timestamps.
+func (r *Registry) SaveChecked() error {
+	if !r.dryRun && !r.readOnly {
+		if err := r.checkContentUnmodified(); err != nil {
+			return err
+		}
+	}
+
+	return r.Save()
+}
+
+// checkContentUnmodified returns ErrRegistryModified if the contents of the
+// registry file differ from those last loaded or saved
+func (r *Registry) checkContentUnmodified() error {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read registry file: %w", err)
+	}
+
+	if r.fileInfo == nil || sha256.Sum256(data) != r.contentHash {
+		return ErrRegistryModified
+	}
+
+	return nil
+}
+
+// fileUnchanged reports whether two stats of a file describe the same contents
+func fileUnchanged(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// load reads the registry from disk
+func (r *Registry) load() error {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read registry file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read registry file: %w", err)
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read registry file: %w", err)
+	}
+
+	regData, err := r.decode(data)
+	if err != nil {
+		return err
+	}
+
+	included, err := loadIncludes(r.path, regData.Includes)
+	if err != nil {
+		return err
+	}
+
+	r.setData(regData, included)
+	r.fileInfo = info
+	r.contentHash = sha256.Sum256(data)
+
+	r.log().Debug("loaded registry", "path", r.path, "assignments", len(r.assignments), "blockedPorts", len(r.blockedPorts), "allowedPorts", len(r.allowedPorts), "included", len(r.included))
+	return nil
+}
+
+// setData replaces the in-memory registry with decoded file contents and the
+// assignments of its included files
+func (r *Registry) setData(regData registryData, included []IncludedAssignment) {
+	r.startPort = regData.StartPort
+	r.endPort = regData.EndPort
+	r.protectAutoRange = regData.ProtectAutoRange
+	r.compact = regData.Compact
+	r.includes = regData.Includes
+	r.included = included
+	r.onChange = regData.OnChange
+	r.audit = regData.Audit
+	r.assignments = regData.Assignments
+	r.blockedPorts = regData.BlockedPorts
+	r.allowedPorts = regData.AllowedPorts
+}
+
+// decode parses the contents of a registry file in the registry file's
+// format, migrates it to CurrentVersion, and checks its schema. Empty or
+// unparsable contents are ErrCorruptRegistry.
+func (r *Registry) decode(data []byte) (registryData, error) {
+	var regData registryData
+	if len(bytes.TrimSpace(data)) == 0 {
+		return regData, fmt.Errorf("%w: file is empty", ErrCorruptRegistry)
+	}
+	if err := r.unmarshal(data, &regData); err != nil {
+		return regData, fmt.Errorf("%w: failed to unmarshal registry: %w", ErrCorruptRegistry, err)
+	}
+
+	if err := migrate(&regData); err != nil {
+		return regData, err
+	}
+
+	if !r.lenient {
+		if err := validateSchema(regData); err != nil {
+			return regData, err
+		}
+	}
+
+	return regData, nil
+}
+
+// isYAML reports whether the registry file is stored as YAML, based on its extension
+func (r *Registry) isYAML() bool {
+	switch strings.ToLower(filepath.Ext(r.path)) {
+	case ".yaml", ".yml":
+		return true
+	default:
 		return false
 	}
+}
 
-	return port == singlePort
+// marshal encodes registry data in the registry file's format
+func (r *Registry) marshal(data registryData) ([]byte, error) {
+	if r.isYAML() {
+		return yaml.Marshal(data)
+	}
+	if data.Compact {
+		return json.Marshal(data)
+	}
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// unmarshal decodes registry data in the registry file's format
+func (r *Registry) unmarshal(data []byte, regData *registryData) error {
+	if r.isYAML() {
+		return yaml.Unmarshal(data, regData)
+	}
+	return json.Unmarshal(data, regData)
+}
+
+// validateSchema checks the structure of a parsed registry file. It rejects
+// assignments without a port and blocked entries without ports, returning one
+// error per offending entry.
+func validateSchema(data registryData) error {
+	var errs []error
+	for i, a := range data.Assignments {
+		if a.Port == 0 {
+			errs = append(errs, fmt.Errorf("%w: assignments[%d] (%q) has no port", ErrMalformedRegistry, i, a.Description))
+		}
+	}
+	for i, bp := range data.BlockedPorts {
+		if strings.TrimSpace(bp.Ports) == "" {
+			errs = append(errs, fmt.Errorf("%w: blockedPorts[%d] (%q) has no ports", ErrMalformedRegistry, i, bp.Description))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sortedAssignments returns a copy of assignments sorted by port so the saved
+// file does not depend on the order operations were performed in
+func sortedAssignments(assignments []Assignment) []Assignment {
+	sorted := slices.Clone(assignments)
+	slices.SortStableFunc(sorted, func(a, b Assignment) int {
+		return a.Port - b.Port
+	})
+	return sorted
+}
+
+// sortedBlockedPorts returns a copy of blocked sorted by starting port.
+// Unparsable specs are kept at the end in their original order.
+func sortedBlockedPorts(blocked []BlockedPort) []BlockedPort {
+	startOf := func(bp BlockedPort) int {
+		ranges, err := parseBlockedSpec(bp.Ports)
+		if err != nil {
+			return maxPort + 1
+		}
+		return ranges[0].start
+	}
+
+	sorted := slices.Clone(blocked)
+	slices.SortStableFunc(sorted, func(a, b BlockedPort) int {
+		return startOf(a) - startOf(b)
+	})
+	return sorted
+}
+
+// isPortBlocked checks if a port is in any blocked range
+func (r *Registry) isPortBlocked(port int) bool {
+	_, ok := r.GetBlockedPort(port)
+	return ok
+}
+
+// canonicalPath returns path in absolute, cleaned form so the same project
+// directory is stored and matched the same way however it was typed. Relative
+// paths are resolved against the current directory. The empty path stays empty.
+func canonicalPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// isStalePath reports whether an assignment path is set but no longer exists
+func isStalePath(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// assignmentsInRanges returns the assignments with ports in any of ranges.
+// Each assignment is returned once even if ranges overlap.
+func (r *Registry) assignmentsInRanges(ranges []blockedRange) []Assignment {
+	var found []Assignment
+	for _, a := range r.assignments {
+		if slices.ContainsFunc(ranges, func(br blockedRange) bool { return a.Port >= br.start && a.Port <= br.end }) {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// canAutoAssign checks if a port may be chosen by auto-assignment for protocol
+func (r *Registry) canAutoAssign(av *availability, port int, protocol string) bool {
+	if !av.isAvailable(port) {
+		return false
+	}
+	return !r.verify || CheckBindable(port, protocol)
+}
+
+// findNextAvailablePort finds the lowest available port for protocol between
+// the start and end ports
+func (r *Registry) findNextAvailablePort(protocol string) int {
+	return r.findAvailablePortIn(r.StartPort(), r.EndPort(), protocol)
+}
+
+// findAvailablePortIn finds the lowest available port for protocol from
+// startPort to endPort inclusive
+func (r *Registry) findAvailablePortIn(startPort, endPort int, protocol string) int {
+	av := r.newAvailability()
+	log := r.log()
+	log.Debug("searching for available port", "start", startPort, "end", endPort)
+
+	for port := startPort; port <= endPort; port++ {
+		// Skip ports outside the allowed ranges
+		if next := av.nextAllowed(port); next != port {
+			if next == -1 {
+				log.Debug("skipped ports", "start", port, "end", endPort, "reason", "not allowed")
+				break
+			}
+			log.Debug("skipped ports", "start", port, "end", next-1, "reason", "not allowed")
+			port = next - 1
+			continue
+		}
+
+		// Skip whole blocked ranges at once
+		if end := av.blockedEnd(port); end != -1 {
+			log.Debug("skipped ports", "start", port, "end", end, "reason", "blocked")
+			port = end
+			continue
+		}
+		if av.used[port] {
+			log.Debug("skipped port", "port", port, "reason", "assigned")
+			continue
+		}
+		if !r.canAutoAssign(av, port, protocol) {
+			log.Debug("skipped port", "port", port, "reason", "in use")
+			continue
+		}
+
+		log.Debug("selected port", "port", port)
+		return port
+	}
+
+	log.Debug("no available port", "start", startPort, "end", endPort)
+	return -1
+}
+
+// findAvailableBlock finds the first port of the lowest run of count consecutive
+// ports available for protocol between the start and end ports
+func (r *Registry) findAvailableBlock(count int, protocol string) int {
+	runStart := -1
+	runLength := 0
+	av := r.newAvailability()
+
+	for port := r.StartPort(); port <= r.EndPort(); port++ {
+		if !r.canAutoAssign(av, port, protocol) {
+			runLength = 0
+			continue
+		}
+
+		if runLength == 0 {
+			runStart = port
+		}
+		runLength++
+
+		if runLength == count {
+			r.log().Debug("selected port block", "start", runStart, "count", count)
+			return runStart
+		}
+	}
+
+	r.log().Debug("no available port block", "count", count, "start", r.StartPort(), "end", r.EndPort())
+	return -1
+}
+
+// parsePortRange parses a range specification into its first and last port
+func parsePortRange(rangeSpec string) (int, int, error) {
+	// Check if it's a range (contains hyphen)
+	if strings.Contains(rangeSpec, "-") {
+		parts := strings.Split(rangeSpec, "-")
+		if len(parts) != 2 {
+			return 0, 0, fmt.Errorf("%w: %s", ErrInvalidPortRange, rangeSpec)
+		}
+
+		start, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+		end, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+
+		if err1 != nil || err2 != nil {
+			return 0, 0, fmt.Errorf("%w: %s", ErrInvalidPortRange, rangeSpec)
+		}
+
+		return start, end, nil
+	}
+
+	// Single port
+	singlePort, err := strconv.Atoi(strings.TrimSpace(rangeSpec))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %s", ErrInvalidPortRange, rangeSpec)
+	}
+
+	return singlePort, singlePort, nil
+}
+
+// ParsePortRange parses a port or range spec such as "8000" or "8000-8100" and
+// returns its inclusive bounds. It returns an error wrapping ErrInvalidPortRange
+// for malformed specs.
+func ParsePortRange(rangeSpec string) (start, end int, err error) {
+	return validatePortRange(rangeSpec)
+}
+
+// validateProtocol checks that protocol is empty, ProtocolTCP, or ProtocolUDP
+func validateProtocol(protocol string) error {
+	switch protocol {
+	case "", ProtocolTCP, ProtocolUDP:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s (must be %s or %s)", ErrInvalidProtocol, protocol, ProtocolTCP, ProtocolUDP)
+	}
+}
+
+// validatePort checks that a port is a valid port number
+func validatePort(port int) error {
+	if port < minPort || port > maxPort {
+		return fmt.Errorf("%w: %d (must be between %d and %d)", ErrInvalidPort, port, minPort, maxPort)
+	}
+	return nil
+}
+
+// validatePortRange parses a range specification and checks that it only
+// contains valid port numbers
+func validatePortRange(rangeSpec string) (int, int, error) {
+	start, end, err := parsePortRange(rangeSpec)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if start < minPort || start > maxPort || end < minPort || end > maxPort {
+		return 0, 0, fmt.Errorf("%w: %s (ports must be between %d and %d)", ErrInvalidPortRange, rangeSpec, minPort, maxPort)
+	}
+
+	if start > end {
+		return 0, 0, fmt.Errorf("%w: %s (start is greater than end)", ErrInvalidPortRange, rangeSpec)
+	}
+
+	return start, end, nil
+}
+
+// isPortInRange checks if a port is covered by a blocked spec, including any
+// of the comma-separated components of a multi-range spec
+func isPortInRange(port int, rangeSpec string) bool {
+	ranges, err := parseBlockedSpec(rangeSpec)
+	if err != nil {
+		return false
+	}
+
+	for _, br := range ranges {
+		if port >= br.start && port <= br.end {
+			return true
+		}
+	}
+	return false
 }
@@ -1,9 +1,13 @@
 package registry
 
 import (
+	"bytes"
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -87,6 +91,45 @@ func TestInit(t *testing.T) {
 	})
 }
 
+func TestInitWith(t *testing.T) {
+	t.Run("seeds given blocked ports", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		blocked := []BlockedPort{{Ports: "9000-9010", Description: "team services"}}
+
+		require.NoError(t, reg.InitWith(blocked))
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, blocked, reg2.ListBlockedPorts())
+	})
+
+	t.Run("creates empty registry", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		require.NoError(t, reg.InitWith(nil))
+
+		data, err := os.ReadFile(reg.path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"blockedPorts": []`)
+	})
+
+	t.Run("rejects invalid blocked ports", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.InitWith([]BlockedPort{{Ports: "abc"}})
+		assert.ErrorIs(t, err, ErrInvalidPortRange)
+
+		_, err = os.Stat(reg.path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("init uses defaults", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Init())
+		assert.Equal(t, DefaultBlockedPorts(), reg.ListBlockedPorts())
+	})
+}
+
 func TestAssignPort(t *testing.T) {
 	t.Run("assigns available port", func(t *testing.T) {
 		reg := createTestRegistry(t)
@@ -111,6 +154,19 @@ func TestAssignPort(t *testing.T) {
 		assert.Contains(t, err.Error(), "project1")
 	})
 
+	t.Run("returns conflicting assignment", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.AssignPort(8000, "project1", "/path/to/project1")
+		require.NoError(t, err)
+
+		err = reg.AssignPort(8000, "project2", "")
+		var conflict *PortConflictError
+		require.True(t, errors.As(err, &conflict))
+		assert.Equal(t, Assignment{Port: 8000, Description: "project1", Path: "/path/to/project1", CreatedAt: testNow}, conflict.Assignment)
+		assert.Equal(t, "port is already assigned: port 8000 is already assigned to 'project1'", err.Error())
+	})
+
 	t.Run("fails on blocked port", func(t *testing.T) {
 		reg := createTestRegistry(t)
 		reg.blockedPorts = []BlockedPort{{Ports: "3000-3010"}}
@@ -118,6 +174,237 @@ func TestAssignPort(t *testing.T) {
 		err := reg.AssignPort(3005, "project", "")
 		assert.ErrorIs(t, err, ErrPortBlocked)
 	})
+
+	t.Run("fails on invalid port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		for _, port := range []int{-1, 0, 65536, 70000} {
+			err := reg.AssignPort(port, "project", "")
+			assert.ErrorIs(t, err, ErrInvalidPort)
+		}
+		assert.Empty(t, reg.assignments)
+
+		require.NoError(t, reg.AssignPort(1, "project", ""))
+		require.NoError(t, reg.AssignPort(65535, "project", ""))
+	})
+}
+
+func TestAssign(t *testing.T) {
+	t.Run("stores all assignment fields", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		a := Assignment{Port: 8000, Description: "test", Path: "/path", Tags: []string{"env:staging", "team:payments"}, CreatedAt: testNow}
+		require.NoError(t, reg.Assign(a))
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{a}, reg2.assignments)
+	})
+
+	t.Run("auto-assigns next port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 3100}}
+
+		port, err := reg.AssignNext(Assignment{Port: 9999, Tags: []string{"env:dev"}})
+		require.NoError(t, err)
+		assert.Equal(t, 3101, port)
+		assert.Equal(t, Assignment{Port: 3101, Tags: []string{"env:dev"}, CreatedAt: testNow}, reg.assignments[1])
+	})
+
+	t.Run("auto-assigns next block", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		ports, err := reg.AssignNextBlock(2, Assignment{Tags: []string{"env:dev"}})
+		require.NoError(t, err)
+		assert.Equal(t, []int{3100, 3101}, ports)
+		assert.Equal(t, []Assignment{
+			{Port: 3100, Tags: []string{"env:dev"}, CreatedAt: testNow},
+			{Port: 3101, Tags: []string{"env:dev"}, CreatedAt: testNow},
+		}, reg.assignments)
+	})
+}
+
+func TestProtectAutoRange(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.json")
+	require.NoError(t, os.WriteFile(tempFile, []byte(`{"startPort":3100,"endPort":3199,"protectAutoRange":true,"assignments":[],"blockedPorts":[]}`), 0644))
+
+	reg, err := New(tempFile)
+	require.NoError(t, err)
+
+	err = reg.AssignPort(3150, "manual", "")
+	assert.ErrorIs(t, err, ErrPortInAutoRange)
+
+	err = reg.Reserve(3100, "manual")
+	assert.ErrorIs(t, err, ErrPortInAutoRange)
+
+	// Outside the window and auto-assignment are unaffected
+	require.NoError(t, reg.AssignPort(3200, "manual", ""))
+	port, err := reg.AssignNextAvailable("auto", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3100, port)
+
+	reg.SetAllowAutoRange(true)
+	require.NoError(t, reg.AssignPort(3150, "forced", ""))
+
+	// The policy survives a save
+	reg2, err := New(tempFile)
+	require.NoError(t, err)
+	err = reg2.AssignPort(3151, "manual", "")
+	assert.ErrorIs(t, err, ErrPortInAutoRange)
+}
+
+func TestAssignmentNames(t *testing.T) {
+	t.Run("names are unique per path", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Assign(Assignment{Port: 8000, Name: "web", Path: "/project1"}))
+		require.NoError(t, reg.Assign(Assignment{Port: 8001, Name: "grpc", Path: "/project1"}))
+		require.NoError(t, reg.Assign(Assignment{Port: 8002, Name: "web", Path: "/project2"}))
+
+		err := reg.Assign(Assignment{Port: 8003, Name: "web", Path: "/project1/"})
+		assert.ErrorIs(t, err, ErrNameInUse)
+
+		_, err = reg.AssignNext(Assignment{Name: "grpc", Path: "/project1"})
+		assert.ErrorIs(t, err, ErrNameInUse)
+
+		_, err = reg.AssignNextBlock(2, Assignment{Name: "metrics", Path: "/project1"})
+		assert.Error(t, err)
+
+		err = reg.Update(Assignment{Port: 8001, Name: "web", Path: "/project1"})
+		assert.ErrorIs(t, err, ErrNameInUse)
+
+		assignments := reg.FindByPath("/project1")
+		require.Len(t, assignments, 2)
+		assert.Equal(t, "web", assignments[0].Name)
+		assert.Equal(t, "grpc", assignments[1].Name)
+	})
+
+	t.Run("names are saved", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Assign(Assignment{Port: 8000, Name: "web", Path: "/project1"}))
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		a, ok := reg2.GetAssignment(8000)
+		require.True(t, ok)
+		assert.Equal(t, "web", a.Name)
+	})
+
+	t.Run("validate reports duplicate names", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Name: "web", Path: "/project1"},
+			{Port: 8001, Name: "web", Path: "/project1"},
+		}
+
+		errs := reg.Validate()
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], ErrNameInUse)
+	})
+}
+
+func TestAssignProtocol(t *testing.T) {
+	t.Run("port may be assigned once per protocol", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Assign(Assignment{Port: 5000, Description: "web"}))
+		require.NoError(t, reg.Assign(Assignment{Port: 5000, Description: "stats", Protocol: ProtocolUDP}))
+
+		err := reg.Assign(Assignment{Port: 5000, Description: "dup", Protocol: ProtocolTCP})
+		assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+
+		err = reg.Assign(Assignment{Port: 5000, Description: "dup", Protocol: ProtocolUDP})
+		assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+
+		assert.False(t, reg.IsPortAvailable(5000))
+		assert.Empty(t, reg.Validate())
+
+		require.NoError(t, reg.Update(Assignment{Port: 5000, Description: "statsd", Protocol: ProtocolUDP}))
+		a, ok := reg.GetAssignment(5000)
+		require.True(t, ok)
+		assert.Equal(t, "web", a.Description)
+	})
+
+	t.Run("lookups by protocol", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Assign(Assignment{Port: 5000, Description: "stats", Protocol: ProtocolUDP}))
+		require.NoError(t, reg.Assign(Assignment{Port: 5000, Description: "web"}))
+		require.NoError(t, reg.AssignPort(5001, "api", ""))
+
+		a, ok := reg.GetAssignment(5000)
+		require.True(t, ok)
+		assert.Equal(t, "web", a.Description)
+
+		_, err := reg.LookupAssignment(5000, "")
+		assert.ErrorIs(t, err, ErrAmbiguousProtocol)
+		a, err = reg.LookupAssignment(5000, ProtocolUDP)
+		require.NoError(t, err)
+		assert.Equal(t, "stats", a.Description)
+		a, err = reg.LookupAssignment(5001, "")
+		require.NoError(t, err)
+		assert.Equal(t, "api", a.Description)
+		_, err = reg.LookupAssignment(5001, ProtocolUDP)
+		assert.ErrorIs(t, err, ErrPortNotAssigned)
+		_, err = reg.LookupAssignment(5001, "sctp")
+		assert.ErrorIs(t, err, ErrInvalidProtocol)
+
+		assert.ErrorIs(t, reg.UnassignPort(5000), ErrAmbiguousProtocol)
+		assert.ErrorIs(t, reg.MovePort(5000, 6000), ErrAmbiguousProtocol)
+		assert.ErrorIs(t, reg.UpdateAssignment(5000, "x", ""), ErrAmbiguousProtocol)
+		assert.Len(t, reg.assignments, 3)
+
+		require.NoError(t, reg.MoveProtocol(5000, 6000, ProtocolUDP))
+		a, err = reg.LookupAssignment(6000, "")
+		require.NoError(t, err)
+		assert.Equal(t, ProtocolUDP, a.Protocol)
+
+		require.NoError(t, reg.MoveProtocol(6000, 5000, ProtocolUDP))
+		require.NoError(t, reg.UnassignProtocol(5000, ProtocolTCP))
+		a, err = reg.LookupAssignment(5000, "")
+		require.NoError(t, err)
+		assert.Equal(t, "stats", a.Description)
+		require.NoError(t, reg.UnassignPort(5000))
+		assert.Equal(t, []int{5001}, assignmentPorts(reg.assignments))
+	})
+
+	t.Run("rejects unknown protocol", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.Assign(Assignment{Port: 5000, Protocol: "sctp"})
+		assert.ErrorIs(t, err, ErrInvalidProtocol)
+
+		_, err = reg.AssignNextBlock(2, Assignment{Protocol: "sctp"})
+		assert.ErrorIs(t, err, ErrInvalidProtocol)
+		assert.Empty(t, reg.assignments)
+	})
+
+	t.Run("defaults to tcp", func(t *testing.T) {
+		assert.Equal(t, ProtocolTCP, Assignment{}.EffectiveProtocol())
+		assert.Equal(t, ProtocolUDP, Assignment{Protocol: ProtocolUDP}.EffectiveProtocol())
+	})
+}
+
+func TestReserve(t *testing.T) {
+	t.Run("reserved port is unavailable", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		require.NoError(t, reg.Reserve(3100, "future project"))
+		assert.Equal(t, []Assignment{{Port: 3100, Description: "future project", Reserved: true, CreatedAt: testNow}}, reg.assignments)
+		assert.False(t, reg.IsPortAvailable(3100))
+
+		port, err := reg.NextAvailable()
+		require.NoError(t, err)
+		assert.Equal(t, 3101, port)
+
+		err = reg.AssignPort(3100, "project", "")
+		assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+	})
+
+	t.Run("fails on blocked port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.blockedPorts = []BlockedPort{{Ports: "3100"}}
+
+		err := reg.Reserve(3100, "")
+		assert.ErrorIs(t, err, ErrPortBlocked)
+	})
 }
 
 func TestAssignNextAvailable(t *testing.T) {
@@ -139,31 +426,934 @@ func TestAssignNextAvailable(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, 3106, port)
 	})
-}
 
-func TestUnassignPort(t *testing.T) {
-	t.Run("unassigns existing port", func(t *testing.T) {
+	t.Run("starts from start port in registry file", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"startPort": 8000, "assignments": [], "blockedPorts": []}`), 0644))
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, 8000, reg.StartPort())
+
+		port, err := reg.AssignNextAvailable("test", "")
+		require.NoError(t, err)
+		assert.Equal(t, 8000, port)
+
+		// Start port is preserved on save
+		reg2, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, 8000, reg2.StartPort())
+	})
+
+	t.Run("stops at end port in registry file", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"startPort": 3100, "endPort": 3101, "assignments": [], "blockedPorts": []}`), 0644))
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, 3101, reg.EndPort())
+
+		port, err := reg.AssignNextAvailable("test", "")
+		require.NoError(t, err)
+		assert.Equal(t, 3100, port)
+
+		port, err = reg.AssignNextAvailable("test", "")
+		require.NoError(t, err)
+		assert.Equal(t, 3101, port)
+
+		_, err = reg.AssignNextAvailable("test", "")
+		assert.ErrorIs(t, err, ErrNoPortsAvailable)
+
+		// End port is preserved on save
+		reg2, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, 3101, reg2.EndPort())
+	})
+
+	t.Run("end port override is not saved", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 3100}}
+		reg.SetEndPort(3100)
+
+		_, err := reg.AssignNextAvailable("test", "")
+		assert.ErrorIs(t, err, ErrNoPortsAvailable)
+
+		require.NoError(t, reg.Save())
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultEndPort, reg2.EndPort())
+	})
+
+	t.Run("start port override is not saved", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetStartPort(9000)
+
+		port, err := reg.AssignNextAvailable("test", "")
+		require.NoError(t, err)
+		assert.Equal(t, 9000, port)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultStartPort, reg2.StartPort())
+	})
+}
+
+func TestNextAvailable(t *testing.T) {
+	t.Run("returns next port without assigning it", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 3100}}
+		reg.blockedPorts = []BlockedPort{{Ports: "3101"}}
+
+		port, err := reg.NextAvailable()
+		require.NoError(t, err)
+		assert.Equal(t, 3102, port)
+		assert.Len(t, reg.assignments, 1)
+	})
+
+	t.Run("fails when no ports are available", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.blockedPorts = []BlockedPort{{Ports: "3100-65535"}}
+
+		_, err := reg.NextAvailable()
+		assert.ErrorIs(t, err, ErrNoPortsAvailable)
+	})
+}
+
+func TestEnsureAssignment(t *testing.T) {
+	reg := createTestRegistry(t)
+	require.NoError(t, reg.AssignPort(3100, "other", "/code/other"))
+
+	port, created, err := reg.EnsureAssignment(Assignment{Description: "shop", Path: "/code/shop"})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 3101, port)
+
+	port, created, err = reg.EnsureAssignment(Assignment{Description: "ignored", Path: "/code/shop/"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, 3101, port)
+	assert.Len(t, reg.ListAssignments(), 2)
+
+	// A name only matches the port with that name
+	port, created, err = reg.EnsureAssignment(Assignment{Name: "grpc", Path: "/code/shop"})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 3102, port)
+
+	port, created, err = reg.EnsureAssignment(Assignment{Name: "grpc", Path: "/code/shop"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, 3102, port)
+
+	_, _, err = reg.EnsureAssignment(Assignment{Description: "no path"})
+	require.Error(t, err)
+}
+
+func TestAssignNextInRange(t *testing.T) {
+	reg := createTestRegistry(t)
+	require.NoError(t, reg.BlockPorts("8000", ""))
+	require.NoError(t, reg.AssignPort(8001, "project1", ""))
+
+	port, err := reg.AssignNextInRange(8000, 8002, "project2", "")
+	require.NoError(t, err)
+	assert.Equal(t, 8002, port)
+
+	_, err = reg.AssignNextInRange(8000, 8002, "project3", "")
+	require.ErrorIs(t, err, ErrNoPortsAvailable)
+	assert.Contains(t, err.Error(), "between 8000 and 8002")
+
+	// Ports below the auto-assignment window can be used
+	port, err = reg.AssignNextInRange(100, 200, "project3", "")
+	require.NoError(t, err)
+	assert.Equal(t, 100, port)
+
+	_, err = reg.AssignNextInRange(9000, 8000, "project4", "")
+	require.ErrorIs(t, err, ErrInvalidPortRange)
+	_, err = reg.AssignNextInRange(0, 8000, "project4", "")
+	require.ErrorIs(t, err, ErrInvalidPortRange)
+}
+
+func TestAssignBlock(t *testing.T) {
+	t.Run("assigns consecutive ports", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		ports, err := reg.AssignBlock(4, "test", "/path")
+		require.NoError(t, err)
+		assert.Equal(t, []int{3100, 3101, 3102, 3103}, ports)
+		assert.Len(t, reg.assignments, 4)
+		for _, a := range reg.assignments {
+			assert.Equal(t, "test", a.Description)
+			assert.Equal(t, "/path", a.Path)
+		}
+	})
+
+	t.Run("skips runs that are too short", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 3102}}
+		reg.blockedPorts = []BlockedPort{{Ports: "3105-3106"}}
+
+		ports, err := reg.AssignBlock(3, "test", "")
+		require.NoError(t, err)
+		assert.Equal(t, []int{3107, 3108, 3109}, ports)
+	})
+
+	t.Run("fails without assigning when no run is long enough", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetStartPort(65530)
+
+		ports, err := reg.AssignBlock(10, "test", "")
+		assert.ErrorIs(t, err, ErrNoPortsAvailable)
+		assert.Nil(t, ports)
+		assert.Empty(t, reg.assignments)
+	})
+
+	t.Run("does not run past the end port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetStartPort(3100)
+		reg.SetEndPort(3108)
+
+		ports, err := reg.AssignBlock(10, "test", "")
+		assert.ErrorIs(t, err, ErrNoPortsAvailable)
+		assert.Nil(t, ports)
+		assert.Empty(t, reg.assignments)
+
+		ports, err = reg.AssignBlock(9, "test", "")
+		require.NoError(t, err)
+		assert.Equal(t, []int{3100, 3101, 3102, 3103, 3104, 3105, 3106, 3107, 3108}, ports)
+	})
+
+	t.Run("fails on invalid count", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		_, err := reg.AssignBlock(0, "test", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestUnassignPort(t *testing.T) {
+	t.Run("unassigns existing port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Description: "project1"},
+			{Port: 8001, Description: "project2"},
+		}
+		
+		err := reg.UnassignPort(8000)
+		require.NoError(t, err)
+		
+		assert.Len(t, reg.assignments, 1)
+		assert.Equal(t, 8001, reg.assignments[0].Port)
+	})
+
+	t.Run("fails on non-assigned port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		
+		err := reg.UnassignPort(8000)
+		assert.ErrorIs(t, err, ErrPortNotAssigned)
+	})
+}
+
+func TestUnassignByPath(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8000, Path: "/project1"},
+		{Port: 8001, Path: "/project2"},
+		{Port: 8002, Path: "/project1/"},
+	}
+
+	count, err := reg.UnassignByPath("/project1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []Assignment{{Port: 8001, Path: "/project2"}}, reg.assignments)
+
+	count, err = reg.UnassignByPath("/project3")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	reg2, err := New(reg.path)
+	require.NoError(t, err)
+	assert.Equal(t, reg.assignments, reg2.assignments)
+}
+
+func TestUnassignByDescription(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8000, Description: "foo"},
+		{Port: 8001, Description: "foobar"},
+		{Port: 8002, Description: "foo"},
+	}
+
+	assert.Len(t, reg.FindByDescription("foo"), 2)
+
+	count, err := reg.UnassignByDescription("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []Assignment{{Port: 8001, Description: "foobar"}}, reg.assignments)
+
+	count, err = reg.UnassignByDescription("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestUpdateAssignment(t *testing.T) {
+	t.Run("updates description and path", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Description: "project1", Path: "/path1"},
+			{Port: 8001, Description: "project2"},
+		}
+
+		err := reg.UpdateAssignment(8000, "renamed", "/path2")
+		require.NoError(t, err)
+
+		assert.Equal(t, Assignment{Port: 8000, Description: "renamed", Path: "/path2"}, reg.assignments[0])
+		assert.Equal(t, Assignment{Port: 8001, Description: "project2"}, reg.assignments[1])
+
+		// Verify it was persisted
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, reg.assignments, reg2.assignments)
+	})
+
+	t.Run("fails on non-assigned port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.UpdateAssignment(8000, "project", "")
+		assert.ErrorIs(t, err, ErrPortNotAssigned)
+	})
+
+	t.Run("keeps the assignment when saving fails", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "project1", Path: "/path1"}}
+		reg.SetReadOnly(true)
+
+		err := reg.UpdateAssignment(8000, "renamed", "/path2")
+		require.ErrorIs(t, err, ErrReadOnly)
+		assert.Equal(t, []Assignment{{Port: 8000, Description: "project1", Path: "/path1"}}, reg.assignments)
+	})
+}
+
+func TestMovePort(t *testing.T) {
+	t.Run("moves assignment", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "project1", Path: "/path1"}}
+
+		err := reg.MovePort(8000, 8005)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Assignment{{Port: 8005, Description: "project1", Path: "/path1"}}, reg.assignments)
+		assert.True(t, reg.IsPortAvailable(8000))
+	})
+
+	t.Run("fails on non-assigned source port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.MovePort(8000, 8005)
+		assert.ErrorIs(t, err, ErrPortNotAssigned)
+	})
+
+	t.Run("fails on assigned target port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000}, {Port: 8005, Description: "project2"}}
+
+		err := reg.MovePort(8000, 8005)
+		assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+		assert.Equal(t, 8000, reg.assignments[0].Port)
+	})
+
+	t.Run("fails on blocked target port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000}}
+		reg.blockedPorts = []BlockedPort{{Ports: "8005"}}
+
+		err := reg.MovePort(8000, 8005)
+		assert.ErrorIs(t, err, ErrPortBlocked)
+		assert.Equal(t, 8000, reg.assignments[0].Port)
+	})
+
+	t.Run("keeps the port when saving fails", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000}}
+		reg.SetReadOnly(true)
+
+		err := reg.MovePort(8000, 8005)
+		require.ErrorIs(t, err, ErrReadOnly)
+		assert.Equal(t, 8000, reg.assignments[0].Port)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("replaces assignment", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "project1"}, {Port: 8001}}
+
+		a := Assignment{Port: 8000, Description: "project1", Notes: "Runs the\nbackground workers", Tags: []string{"env:dev"}}
+		require.NoError(t, reg.Update(a))
+		assert.Equal(t, []Assignment{a, {Port: 8001}}, reg.assignments)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, reg.assignments, reg2.assignments)
+	})
+
+	t.Run("fails on non-assigned port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.Update(Assignment{Port: 8000})
+		assert.ErrorIs(t, err, ErrPortNotAssigned)
+	})
+
+	t.Run("keeps the assignment when saving fails", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "project1"}}
+		reg.SetReadOnly(true)
+
+		err := reg.Update(Assignment{Port: 8000, Description: "renamed"})
+		require.ErrorIs(t, err, ErrReadOnly)
+		assert.Equal(t, []Assignment{{Port: 8000, Description: "project1"}}, reg.assignments)
+	})
+}
+
+func TestRename(t *testing.T) {
+	t.Run("renames matching descriptions", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Description: "old"},
+			{Port: 8001, Description: "other"},
+			{Port: 8002, Description: "old"},
+		}
+
+		count, err := reg.Rename("old", "new")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{
+			{Port: 8000, Description: "new"},
+			{Port: 8001, Description: "other"},
+			{Port: 8002, Description: "new"},
+		}, reg2.assignments)
+	})
+
+	t.Run("reports zero when nothing matches", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "other"}}
+
+		count, err := reg.Rename("old", "new")
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		_, err = os.Stat(reg.path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("renames by path", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Description: "old", Path: "/project1"},
+			{Port: 8001, Description: "old", Path: "/project2"},
+			{Port: 8002, Description: "grpc", Path: "/project1/"},
+		}
+
+		count, err := reg.RenameByPath("/project1", "new")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, "new", reg.assignments[0].Description)
+		assert.Equal(t, "old", reg.assignments[1].Description)
+		assert.Equal(t, "new", reg.assignments[2].Description)
+	})
+
+	t.Run("keeps descriptions when saving fails", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000, Description: "old", Path: "/project1"}}
+		reg.SetReadOnly(true)
+
+		_, err := reg.Rename("old", "new")
+		require.ErrorIs(t, err, ErrReadOnly)
+		_, err = reg.RenameByPath("/project1", "new")
+		require.ErrorIs(t, err, ErrReadOnly)
+		assert.Equal(t, "old", reg.assignments[0].Description)
+	})
+}
+
+func TestGetAssignment(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8000, Description: "project1", Path: "/path1"},
+		{Port: 8001, Description: "project2"},
+	}
+
+	t.Run("returns assigned port", func(t *testing.T) {
+		a, ok := reg.GetAssignment(8000)
+		assert.True(t, ok)
+		assert.Equal(t, Assignment{Port: 8000, Description: "project1", Path: "/path1"}, a)
+	})
+
+	t.Run("returns false for unassigned port", func(t *testing.T) {
+		a, ok := reg.GetAssignment(8002)
+		assert.False(t, ok)
+		assert.Equal(t, Assignment{}, a)
+	})
+}
+
+func TestGetBlockedPort(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.blockedPorts = []BlockedPort{
+		{Ports: "3000-3010", Description: "Rails ports"},
+		{Ports: "3306", Description: "MySQL"},
+	}
+
+	t.Run("returns matching blocked entry", func(t *testing.T) {
+		bp, ok := reg.GetBlockedPort(3306)
+		assert.True(t, ok)
+		assert.Equal(t, BlockedPort{Ports: "3306", Description: "MySQL"}, bp)
+
+		bp, ok = reg.GetBlockedPort(3005)
+		assert.True(t, ok)
+		assert.Equal(t, "3000-3010", bp.Ports)
+	})
+
+	t.Run("returns false for unblocked port", func(t *testing.T) {
+		bp, ok := reg.GetBlockedPort(3011)
+		assert.False(t, ok)
+		assert.Equal(t, BlockedPort{}, bp)
+	})
+}
+
+func TestBlockPorts(t *testing.T) {
+	t.Run("blocks range", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.BlockPorts("4000-4010", "reserved for CI")
+		require.NoError(t, err)
+
+		assert.Equal(t, []BlockedPort{{Ports: "4000-4010", Description: "reserved for CI"}}, reg.blockedPorts)
+		assert.False(t, reg.IsPortAvailable(4005))
+	})
+
+	t.Run("fails on invalid range", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.BlockPorts("4000-abc", "")
+		assert.ErrorIs(t, err, ErrInvalidPortRange)
+		assert.Empty(t, reg.blockedPorts)
+	})
+
+	t.Run("fails on out of bounds range", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		for _, spec := range []string{"70000-80000", "0", "65000-65536", "3010-3000"} {
+			err := reg.BlockPorts(spec, "")
+			assert.ErrorIs(t, err, ErrInvalidPortRange)
+		}
+		assert.Empty(t, reg.blockedPorts)
+	})
+
+	t.Run("fails on range containing assigned port", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 4005, Description: "project1"}}
+
+		err := reg.BlockPorts("4000-4010", "")
+		assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+		assert.Contains(t, err.Error(), "project1")
+		assert.Empty(t, reg.blockedPorts)
+	})
+}
+
+func TestForceBlockPorts(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{{Port: 4005, Description: "project1"}, {Port: 5000}}
+
+	conflicts, err := reg.ForceBlockPorts("4000-4010", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Assignment{{Port: 4005, Description: "project1"}}, conflicts)
+	assert.Equal(t, []BlockedPort{{Ports: "4000-4010"}}, reg.blockedPorts)
+}
+
+func TestUnblockPorts(t *testing.T) {
+	t.Run("unblocks matching spec", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.blockedPorts = []BlockedPort{{Ports: "3306"}, {Ports: "4000-4010"}, {Ports: "5432"}}
+
+		err := reg.UnblockPorts("4000-4010")
+		require.NoError(t, err)
+
+		assert.Equal(t, []BlockedPort{{Ports: "3306"}, {Ports: "5432"}}, reg.blockedPorts)
+		assert.True(t, reg.IsPortAvailable(4005))
+	})
+
+	t.Run("fails on unknown spec and lists blocked specs", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.blockedPorts = []BlockedPort{{Ports: "3306"}, {Ports: "4000-4010"}}
+
+		err := reg.UnblockPorts("4000")
+		assert.ErrorIs(t, err, ErrPortsNotBlocked)
+		assert.Contains(t, err.Error(), "3306, 4000-4010")
+		assert.Len(t, reg.blockedPorts, 2)
+	})
+}
+
+func TestListBlockedPorts(t *testing.T) {
+	reg := createTestRegistry(t)
+	assert.Empty(t, reg.ListBlockedPorts())
+
+	reg.blockedPorts = []BlockedPort{{Ports: "3306", Description: "MySQL"}, {Ports: "4000-4010"}}
+	assert.Equal(t, reg.blockedPorts, reg.ListBlockedPorts())
+}
+
+func TestSearch(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8000, Description: "Rails app", Path: "/code/shop"},
+		{Port: 8001, Description: "api", Path: "/code/rails-api"},
+		{Port: 8002, Description: "postgres", Path: "/code/db"},
+	}
+
+	tests := []struct {
+		query string
+		ports []int
+		desc  string
+	}{
+		{"rails", []int{8000, 8001}, "matches description and path case-insensitively"},
+		{"SHOP", []int{8000}, "matches path"},
+		{"mysql", []int{}, "no matches"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ports := []int{}
+			for _, a := range reg.Search(tt.query) {
+				ports = append(ports, a.Port)
+			}
+			assert.Equal(t, tt.ports, ports)
+		})
+	}
+}
+
+func TestFilterByTag(t *testing.T) {
+	t.Run("returns assignments with tag", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Tags: []string{"env:staging", "team:payments"}},
+			{Port: 8001, Tags: []string{"env:production"}},
+			{Port: 8002},
+			{Port: 8003, Tags: []string{"env:staging"}},
+		}
+
+		ports := []int{}
+		for _, a := range reg.FilterByTag("env:staging") {
+			ports = append(ports, a.Port)
+		}
+		assert.Equal(t, []int{8000, 8003}, ports)
+		assert.Empty(t, reg.FilterByTag("env"))
+	})
+
+	t.Run("loads files without tags", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"assignments": [{"port": 8000, "description": "old"}], "blockedPorts": []}`), 0644))
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{{Port: 8000, Description: "old"}}, reg.assignments)
+		assert.Empty(t, reg.FilterByTag("env:staging"))
+	})
+}
+
+func TestFilterByTime(t *testing.T) {
+	t.Run("returns assignments created in window", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, CreatedAt: testNow.Add(-48 * time.Hour)},
+			{Port: 8001, CreatedAt: testNow.Add(-24 * time.Hour)},
+			{Port: 8002},
+			{Port: 8003, CreatedAt: testNow},
+		}
+
+		ports := func(assignments []Assignment) []int {
+			ports := []int{}
+			for _, a := range assignments {
+				ports = append(ports, a.Port)
+			}
+			return ports
+		}
+		assert.Equal(t, []int{8000, 8001, 8003}, ports(reg.FilterByTime(time.Time{}, time.Time{})))
+		assert.Equal(t, []int{8001, 8003}, ports(reg.FilterByTime(testNow.Add(-24*time.Hour), time.Time{})))
+		assert.Equal(t, []int{8000, 8001}, ports(reg.FilterByTime(time.Time{}, testNow)))
+		assert.Equal(t, []int{8001}, ports(reg.FilterByTime(testNow.Add(-24*time.Hour), testNow)))
+	})
+
+	t.Run("records creation time on save", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(8000, "project", ""))
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, testNow, reg2.assignments[0].CreatedAt)
+	})
+}
+
+func TestFindByPath(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8000, Path: "/code/shop"},
+		{Port: 8001, Path: "/code/shop/"},
+		{Port: 8002, Path: "/code/shop/api"},
+		{Port: 8003},
+	}
+
+	tests := []struct {
+		path  string
+		ports []int
+		desc  string
+	}{
+		{"/code/shop", []int{8000, 8001}, "matches cleaned paths"},
+		{"/code/./shop/api/", []int{8002}, "cleans query path"},
+		{"/code/api/../shop", []int{8000, 8001}, "resolves parent directories"},
+		{"/code", []int{}, "no matches"},
+		{"", []int{}, "empty path does not match missing paths"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ports := []int{}
+			for _, a := range reg.FindByPath(tt.path) {
+				ports = append(ports, a.Port)
+			}
+			assert.Equal(t, tt.ports, ports)
+		})
+	}
+}
+
+func TestCanonicalPaths(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	t.Run("assign stores absolute paths", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(8000, "project1", "foo"))
+		_, err := reg.AssignNextAvailable("project2", "./foo/")
+		require.NoError(t, err)
+
+		for _, a := range reg.ListAssignments() {
+			assert.Equal(t, filepath.Join(wd, "foo"), a.Path)
+		}
+		assert.Len(t, reg.FindByPath(filepath.Join(wd, "foo")), 2)
+	})
+
+	t.Run("UpdateAssignment stores absolute paths", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(8000, "project1", "/code/shop"))
+		require.NoError(t, reg.UpdateAssignment(8000, "project1", "./foo/"))
+
+		a, ok := reg.GetAssignment(8000)
+		require.True(t, ok)
+		assert.Equal(t, filepath.Join(wd, "foo"), a.Path)
+	})
+
+	t.Run("canonicalizes existing paths", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Path: "/code/shop/"},
+			{Port: 8001, Path: "shop"},
+			{Port: 8002, Path: "/code/api"},
+			{Port: 8003},
+		}
+
+		changed, err := reg.CanonicalizePaths()
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{{Port: 8000, Path: "/code/shop/"}, {Port: 8001, Path: "shop"}}, changed)
+		assert.Equal(t, []Assignment{
+			{Port: 8000, Path: "/code/shop"},
+			{Port: 8001, Path: filepath.Join(wd, "shop")},
+			{Port: 8002, Path: "/code/api"},
+			{Port: 8003},
+		}, reg.ListAssignments())
+
+		changed, err = reg.CanonicalizePaths()
+		require.NoError(t, err)
+		assert.Empty(t, changed)
+	})
+}
+
+func TestDescriptionInUse(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8002, Description: "shop"},
+		{Port: 8000, Description: "shop"},
+		{Port: 8001, Description: "Shop"},
+		{Port: 8003},
+	}
+
+	assert.Equal(t, []int{8000, 8002}, reg.DescriptionInUse("shop", ""))
+	assert.Empty(t, reg.DescriptionInUse("blog", ""))
+	assert.Empty(t, reg.DescriptionInUse("", ""))
+
+	t.Run("same project", func(t *testing.T) {
+		dir := t.TempDir()
+		shop := filepath.Join(dir, "shop")
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Description: "shop", Path: shop, Name: "web"},
+			{Port: 8001, Description: "shop", Path: filepath.Join(dir, "other", "shop")},
+			{Port: 8002, Description: "shop"},
+		}
+
+		assert.Equal(t, []int{8001, 8002}, reg.DescriptionInUse("shop", shop))
+		assert.Equal(t, []int{8001, 8002}, reg.DescriptionInUse("shop", filepath.Join(dir, "x", "..", "shop")))
+		assert.Equal(t, []int{8000, 8001, 8002}, reg.DescriptionInUse("shop", ""))
+	})
+}
+
+func TestStalePaths(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "deleted-project")
+
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8000, Path: dir},
+		{Port: 8001, Path: missing},
+		{Port: 8002},
+	}
+
+	t.Run("finds assignments with missing paths", func(t *testing.T) {
+		assert.Equal(t, []Assignment{{Port: 8001, Path: missing}}, reg.StalePaths())
+		assert.Len(t, reg.assignments, 3)
+	})
+
+	t.Run("prunes assignments with missing paths", func(t *testing.T) {
+		removed, err := reg.PruneStalePaths()
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{{Port: 8001, Path: missing}}, removed)
+		assert.Equal(t, []Assignment{{Port: 8000, Path: dir}, {Port: 8002}}, reg.assignments)
+
+		removed, err = reg.PruneStalePaths()
+		require.NoError(t, err)
+		assert.Empty(t, removed)
+	})
+}
+
+func TestPruneExpired(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{
+		{Port: 8000, Description: "expired", ExpiresAt: testNow.Add(-time.Hour)},
+		{Port: 8001, Description: "expires now", ExpiresAt: testNow},
+		{Port: 8002, Description: "later", ExpiresAt: testNow.Add(time.Hour)},
+		{Port: 8003, Description: "never"},
+	}
+
+	assert.Equal(t, []int{8000, 8001}, assignmentPorts(reg.ExpiredAssignments()))
+
+	removed, err := reg.PruneExpired()
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	assert.Equal(t, []int{8002, 8003}, assignmentPorts(reg.ListAssignments()))
+
+	removed, err = reg.PruneExpired()
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+
+	t.Run("keeps assignments when saving fails", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{
+			{Port: 8000, Description: "expired", ExpiresAt: testNow.Add(-time.Hour)},
+			{Port: 8001, Description: "never"},
+		}
+		reg.SetReadOnly(true)
+
+		_, err := reg.PruneExpired()
+		require.ErrorIs(t, err, ErrReadOnly)
+		assert.Equal(t, []int{8000, 8001}, assignmentPorts(reg.ListAssignments()))
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid registry", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Init())
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+
+		assert.Empty(t, reg.Validate())
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
 		reg := createTestRegistry(t)
 		reg.assignments = []Assignment{
 			{Port: 8000, Description: "project1"},
-			{Port: 8001, Description: "project2"},
+			{Port: 8000, Description: "project2"},
+			{Port: 3005, Description: "project3"},
+			{Port: 70000, Description: "project4"},
+		}
+		reg.blockedPorts = []BlockedPort{
+			{Ports: "3000-3010"},
+			{Ports: "4010-4000"},
 		}
-		
-		err := reg.UnassignPort(8000)
-		require.NoError(t, err)
-		
-		assert.Len(t, reg.assignments, 1)
-		assert.Equal(t, 8001, reg.assignments[0].Port)
-	})
 
-	t.Run("fails on non-assigned port", func(t *testing.T) {
-		reg := createTestRegistry(t)
-		
-		err := reg.UnassignPort(8000)
-		assert.ErrorIs(t, err, ErrPortNotAssigned)
+		errs := reg.Validate()
+		require.Len(t, errs, 4)
+		assert.ErrorIs(t, errs[0], ErrPortAlreadyAssigned)
+		assert.Contains(t, errs[0].Error(), "project1")
+		assert.Contains(t, errs[0].Error(), "project2")
+		assert.ErrorIs(t, errs[1], ErrPortBlocked)
+		assert.Contains(t, errs[1].Error(), "3000-3010")
+		assert.ErrorIs(t, errs[2], ErrInvalidPort)
+		assert.ErrorIs(t, errs[3], ErrInvalidPortRange)
 	})
 }
 
+func TestAvailableInRange(t *testing.T) {
+	reg := createTestRegistry(t)
+	require.NoError(t, reg.AssignPort(8001, "project1", ""))
+	require.NoError(t, reg.BlockPorts("8003-8004", "blocked"))
+
+	assert.Equal(t, []int{8000, 8002, 8005}, reg.AvailableInRange(8000, 8005))
+	assert.Equal(t, []int{8002}, reg.AvailableInRange(8002, 8002))
+	assert.Empty(t, reg.AvailableInRange(8003, 8004))
+	assert.Empty(t, reg.AvailableInRange(8005, 8000))
+	assert.Equal(t, []int{65534, 65535}, reg.AvailableInRange(65534, 70000))
+}
+
+func TestParsePortRangeValidation(t *testing.T) {
+	start, end, err := ParsePortRange("8000-8100")
+	require.NoError(t, err)
+	assert.Equal(t, 8000, start)
+	assert.Equal(t, 8100, end)
+
+	start, end, err = ParsePortRange("8000")
+	require.NoError(t, err)
+	assert.Equal(t, 8000, start)
+	assert.Equal(t, 8000, end)
+
+	for _, spec := range []string{"abc", "8100-8000", "1-2-3", "0-10"} {
+		_, _, err = ParsePortRange(spec)
+		assert.ErrorIs(t, err, ErrInvalidPortRange, spec)
+	}
+}
+
+func TestValidateBlockedPorts(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.blockedPorts = []BlockedPort{
+		{Ports: "3000-3010"},
+		{Ports: "3010-3000"},
+		{Ports: "abc"},
+		{Ports: "1-2-3"},
+		{Ports: "8080"},
+	}
+
+	errs := reg.ValidateBlockedPorts()
+	require.Len(t, errs, 3)
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrInvalidPortRange)
+	}
+	assert.Contains(t, errs[0].Error(), "3010-3000")
+	assert.Contains(t, errs[1].Error(), "abc")
+	assert.Contains(t, errs[2].Error(), "1-2-3")
+
+	reg.blockedPorts = []BlockedPort{{Ports: "3000-3010"}}
+	assert.Empty(t, reg.ValidateBlockedPorts())
+}
+
 func TestIsPortAvailable(t *testing.T) {
 	reg := createTestRegistry(t)
 	reg.assignments = []Assignment{{Port: 8000}}
@@ -203,6 +1393,12 @@ func TestPortRangeParsing(t *testing.T) {
 		{8081, "8080", false, "single port no match"},
 		{5000, "invalid-range", false, "invalid range format"},
 		{5000, "abc-def", false, "non-numeric range"},
+		{4000, "3000-3010,4000,5000-5005", true, "second component of multi-range"},
+		{5003, "3000-3010,4000,5000-5005", true, "third component of multi-range"},
+		{4001, "3000-3010,4000,5000-5005", false, "between multi-range components"},
+		{4000, "3000-3010, 4000", true, "multi-range with whitespace"},
+		{3005, "3000-3010, 4000", true, "first component with whitespace"},
+		{4000, "3000-3010,,4000", false, "empty component"},
 	}
 	
 	for _, tt := range tests {
@@ -212,6 +1408,36 @@ func TestPortRangeParsing(t *testing.T) {
 	}
 }
 
+func TestParsePortRange(t *testing.T) {
+	tests := []struct {
+		rangeSpec string
+		start     int
+		end       int
+		valid     bool
+		desc      string
+	}{
+		{"3000-3010", 3000, 3010, true, "range"},
+		{" 3000 - 3010 ", 3000, 3010, true, "range with whitespace"},
+		{"8080", 8080, 8080, true, "single port"},
+		{"1-2-3", 0, 0, false, "too many hyphens"},
+		{"abc-def", 0, 0, false, "non-numeric range"},
+		{"abc", 0, 0, false, "non-numeric port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			start, end, err := parsePortRange(tt.rangeSpec)
+			if tt.valid {
+				require.NoError(t, err)
+				assert.Equal(t, tt.start, start)
+				assert.Equal(t, tt.end, end)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidPortRange)
+			}
+		})
+	}
+}
+
 func TestSaveAndLoad(t *testing.T) {
 	t.Run("saves and loads registry data", func(t *testing.T) {
 		tempFile := filepath.Join(t.TempDir(), "test.json")
@@ -240,6 +1466,146 @@ func TestSaveAndLoad(t *testing.T) {
 		assert.Equal(t, reg1.blockedPorts, reg2.blockedPorts)
 	})
 
+	t.Run("serializes independent of insertion order", func(t *testing.T) {
+		dir := t.TempDir()
+
+		reg1, err := New(filepath.Join(dir, "test1.json"))
+		require.NoError(t, err)
+		require.NoError(t, reg1.BlockPorts("5432", "PostgreSQL"))
+		require.NoError(t, reg1.BlockPorts("3000-3010", "Rails ports"))
+		require.NoError(t, reg1.AssignPort(8001, "project2", ""))
+		require.NoError(t, reg1.AssignPort(8000, "project1", "/path1"))
+
+		reg2, err := New(filepath.Join(dir, "test2.json"))
+		require.NoError(t, err)
+		require.NoError(t, reg2.AssignPort(8000, "project1", "/path1"))
+		require.NoError(t, reg2.BlockPorts("3000-3010", "Rails ports"))
+		require.NoError(t, reg2.AssignPort(8001, "project2", ""))
+		require.NoError(t, reg2.BlockPorts("5432", "PostgreSQL"))
+
+		data1, err := os.ReadFile(reg1.path)
+		require.NoError(t, err)
+		data2, err := os.ReadFile(reg2.path)
+		require.NoError(t, err)
+		assert.Equal(t, string(data1), string(data2))
+
+		reg3, err := New(reg1.path)
+		require.NoError(t, err)
+		assert.Equal(t, 8000, reg3.ListAssignments()[0].Port)
+		assert.Equal(t, "3000-3010", reg3.ListBlockedPorts()[0].Ports)
+	})
+
+	t.Run("writes compact JSON when configured", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"compact":true,"assignments":[],"blockedPorts":[]}`), 0644))
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		reg.now = func() time.Time { return testNow }
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+
+		data, err := os.ReadFile(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, `{"version":1,"compact":true,"assignments":[{"port":8000,"description":"project1","createdAt":"2024-03-01T12:00:00Z"}],"blockedPorts":[]}`, string(data))
+	})
+
+	t.Run("preserves labels", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Assign(Assignment{Port: 8000, Description: "project1", Label: "red"}))
+
+		data, err := os.ReadFile(reg.path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"label": "red"`)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		a, ok := reg2.GetAssignment(8000)
+		require.True(t, ok)
+		assert.Equal(t, "red", a.Label)
+	})
+
+	t.Run("preserves onChange", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"onChange":"reload-proxy.sh","assignments":[],"blockedPorts":[]}`), 0644))
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, "reload-proxy.sh", reg.OnChange())
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+
+		reg2, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, "reload-proxy.sh", reg2.OnChange())
+	})
+
+	t.Run("rejects malformed entries", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+		data := `{
+			"assignments": [
+				{"port": 8000, "description": "ok"},
+				{"description": "missing port"},
+				{"port": 0, "description": "zero port"}
+			],
+			"blockedPorts": [
+				{"ports": "", "description": "empty"}
+			]
+		}`
+		require.NoError(t, os.WriteFile(tempFile, []byte(data), 0644))
+
+		_, err := New(tempFile)
+		require.ErrorIs(t, err, ErrMalformedRegistry)
+		assert.Contains(t, err.Error(), "assignments[1]")
+		assert.Contains(t, err.Error(), "assignments[2]")
+		assert.Contains(t, err.Error(), "blockedPorts[0]")
+		assert.NotContains(t, err.Error(), "assignments[0]")
+	})
+
+	t.Run("saves and loads YAML registry data", func(t *testing.T) {
+		for _, ext := range []string{".yaml", ".yml"} {
+			tempFile := filepath.Join(t.TempDir(), "test"+ext)
+
+			reg1, err := New(tempFile)
+			require.NoError(t, err)
+
+			reg1.startPort = 8000
+			reg1.assignments = []Assignment{
+				{Port: 8000, Description: "project1", Path: "/path1", Tags: []string{"env:dev"}},
+				{Port: 8001, Description: "project2"},
+			}
+			reg1.blockedPorts = []BlockedPort{
+				{Ports: "3000-3010", Description: "Rails ports"},
+				{Ports: "3306"},
+			}
+			require.NoError(t, reg1.Save())
+
+			data, err := os.ReadFile(tempFile)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "blockedPorts:")
+
+			reg2, err := New(tempFile)
+			require.NoError(t, err)
+			assert.Equal(t, reg1.startPort, reg2.startPort)
+			assert.Equal(t, reg1.assignments, reg2.assignments)
+			assert.Equal(t, reg1.blockedPorts, reg2.blockedPorts)
+		}
+	})
+
+	t.Run("loads hand-written YAML", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.yaml")
+		yamlData := `assignments:
+  - port: 8000
+    description: project1
+blockedPorts:
+  - ports: 3000-3010
+`
+		require.NoError(t, os.WriteFile(tempFile, []byte(yamlData), 0644))
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Equal(t, []Assignment{{Port: 8000, Description: "project1"}}, reg.assignments)
+		assert.Equal(t, []BlockedPort{{Ports: "3000-3010"}}, reg.blockedPorts)
+	})
+
 	t.Run("handles missing directory", func(t *testing.T) {
 		tempDir := t.TempDir()
 		tempFile := filepath.Join(tempDir, "subdir", "test.json")
@@ -257,9 +1623,342 @@ func TestSaveAndLoad(t *testing.T) {
 	})
 }
 
+func TestDryRun(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.SetDryRun(true)
+
+	port, err := reg.AssignNextAvailable("test", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3100, port)
+
+	err = reg.AssignPort(3100, "test", "")
+	assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+
+	_, err = os.Stat(reg.path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSetFileMode(t *testing.T) {
+	t.Run("defaults to 0644 minus the umask", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Save())
+
+		// A file created the usual way gets the umask applied too
+		want := filepath.Join(t.TempDir(), "want")
+		require.NoError(t, os.WriteFile(want, nil, DefaultFileMode))
+		wantInfo, err := os.Stat(want)
+		require.NoError(t, err)
+
+		info, err := os.Stat(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, wantInfo.Mode().Perm(), info.Mode().Perm())
+	})
+
+	t.Run("writes with configured mode", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, os.WriteFile(reg.path+".tmp", []byte("stale"), 0666))
+		reg.SetFileMode(0600)
+		require.NoError(t, reg.Save())
+
+		info, err := os.Stat(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		assert.NoFileExists(t, reg.path+".tmp")
+	})
+}
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	reg := createTestRegistry(t)
+	reg.SetLogger(logger)
+	require.NoError(t, reg.BlockPorts("3100-3102", ""))
+	require.NoError(t, reg.AssignPort(3103, "project1", ""))
+	require.ErrorIs(t, reg.AssignPort(3101, "project2", ""), ErrPortBlocked)
+
+	buf.Reset()
+	port, err := reg.AssignNextAvailable("project2", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3104, port)
+
+	assert.Equal(t, `level=DEBUG msg="searching for available port" start=3100 end=65535
+level=DEBUG msg="skipped ports" start=3100 end=3102 reason=blocked
+level=DEBUG msg="skipped port" port=3103 reason=assigned
+level=DEBUG msg="selected port" port=3104
+level=DEBUG msg="saved registry" path=`+reg.path+` assignments=2 blockedPorts=1
+`, buf.String())
+
+	// Nil discards logs
+	reg.SetLogger(nil)
+	buf.Reset()
+	_, err = reg.AssignNextAvailable("project3", "")
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestSaveRenameRetry(t *testing.T) {
+	t.Run("retries transient rename failures", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		var calls int
+		reg.rename = func(oldpath, newpath string) error {
+			calls++
+			if calls < 3 {
+				return errors.New("sharing violation")
+			}
+			return os.Rename(oldpath, newpath)
+		}
+
+		require.NoError(t, reg.AssignPort(8000, "project", ""))
+		assert.Equal(t, 3, calls)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Len(t, reg2.assignments, 1)
+	})
+
+	t.Run("gives up and removes the temporary file", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		var calls int
+		reg.rename = func(oldpath, newpath string) error {
+			calls++
+			return errors.New("sharing violation")
+		}
+
+		err := reg.AssignPort(8000, "project", "")
+		assert.ErrorContains(t, err, "sharing violation")
+		assert.Equal(t, renameAttempts, calls)
+		assert.NoFileExists(t, reg.path+".tmp")
+		assert.NoFileExists(t, reg.path)
+	})
+}
+
+func TestReadOnly(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.SetReadOnly(true)
+
+	err := reg.AssignPort(8000, "test", "")
+	assert.ErrorIs(t, err, ErrReadOnly)
+
+	_, err = os.Stat(reg.path)
+	assert.True(t, os.IsNotExist(err))
+
+	reg.SetReadOnly(false)
+	require.NoError(t, reg.Save())
+}
+
+func TestReload(t *testing.T) {
+	t.Run("picks up external changes", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg1, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg1.AssignPort(8000, "project1", ""))
+
+		reg2, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg2.AssignPort(8001, "project2", ""))
+
+		require.NoError(t, reg1.Reload())
+		assert.Len(t, reg1.assignments, 2)
+	})
+
+	t.Run("resets when file is missing", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.assignments = []Assignment{{Port: 8000}}
+
+		require.NoError(t, reg.Reload())
+		assert.Empty(t, reg.assignments)
+	})
+}
+
+func TestSaveDetectsExternalModification(t *testing.T) {
+	t.Run("file replaced by another registry", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg1, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg1.AssignPort(8000, "project1", ""))
+
+		reg2, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg2.AssignPort(8001, "project2", ""))
+
+		err = reg1.AssignPort(8002, "project3", "")
+		assert.ErrorIs(t, err, ErrRegistryModified)
+
+		// Reload and retry
+		require.NoError(t, reg1.Reload())
+		require.NoError(t, reg1.AssignPort(8002, "project3", ""))
+
+		reg3, err := New(tempFile)
+		require.NoError(t, err)
+		assert.Len(t, reg3.assignments, 3)
+	})
+
+	t.Run("file edited in place", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"assignments": [{"port": 9000}], "blockedPorts": []}`), 0644))
+
+		err = reg.AssignPort(8001, "project2", "")
+		assert.ErrorIs(t, err, ErrRegistryModified)
+	})
+
+	t.Run("file created after load", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"assignments": [], "blockedPorts": []}`), 0644))
+
+		assert.ErrorIs(t, reg.Save(), ErrRegistryModified)
+	})
+}
+
+func TestSaveChecked(t *testing.T) {
+	t.Run("unmodified file", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg.SaveChecked())
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+		require.NoError(t, reg.SaveChecked())
+
+		reg2, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg2.SaveChecked())
+	})
+
+	t.Run("edit keeping size and modification time", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+
+		info, err := os.Stat(tempFile)
+		require.NoError(t, err)
+		data, err := os.ReadFile(tempFile)
+		require.NoError(t, err)
+		edited := bytes.Replace(data, []byte("8000"), []byte("9000"), 1)
+		require.NotEqual(t, data, edited)
+		require.NoError(t, os.WriteFile(tempFile, edited, 0644))
+		require.NoError(t, os.Chtimes(tempFile, info.ModTime(), info.ModTime()))
+
+		// The stat check alone cannot see the edit
+		require.NoError(t, reg.checkUnmodified())
+		assert.ErrorIs(t, reg.SaveChecked(), ErrRegistryModified)
+
+		// Reload and retry
+		require.NoError(t, reg.Reload())
+		assert.Equal(t, []int{9000}, assignmentPorts(reg.assignments))
+		require.NoError(t, reg.SaveChecked())
+	})
+
+	t.Run("file created after load", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "test.json")
+
+		reg, err := New(tempFile)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(tempFile, []byte(`{"assignments": [], "blockedPorts": []}`), 0644))
+
+		assert.ErrorIs(t, reg.SaveChecked(), ErrRegistryModified)
+	})
+}
+
+// testNow is the fixed clock of registries from createTestRegistry
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
 func createTestRegistry(t *testing.T) *Registry {
 	tempFile := filepath.Join(t.TempDir(), "test.json")
 	reg, err := New(tempFile)
 	require.NoError(t, err)
+	reg.now = func() time.Time { return testNow }
 	return reg
-}
\ No newline at end of file
+}
+
+// assignmentPorts returns the ports of assignments in order
+func assignmentPorts(assignments []Assignment) []int {
+	ports := []int{}
+	for _, a := range assignments {
+		ports = append(ports, a.Port)
+	}
+	return ports
+}
+
+func BenchmarkNextAvailable(b *testing.B) {
+	reg, err := New(filepath.Join(b.TempDir(), "test.json"))
+	require.NoError(b, err)
+
+	// Fill the start of the window so the search has to skip every assignment
+	for port := DefaultStartPort; port < DefaultStartPort+5000; port++ {
+		reg.assignments = append(reg.assignments, Assignment{Port: port})
+	}
+	reg.blockedPorts = DefaultBlockedPorts()
+
+	for b.Loop() {
+		port, err := reg.NextAvailable()
+		if err != nil || port != DefaultStartPort+5000 {
+			b.Fatalf("NextAvailable() = %d, %v", port, err)
+		}
+	}
+}
+
+func TestAssignmentsInRange(t *testing.T) {
+	reg := createTestRegistry(t)
+	require.NoError(t, reg.AssignPort(3105, "web", ""))
+	require.NoError(t, reg.AssignPort(3005, "api", ""))
+	require.NoError(t, reg.AssignPort(4000, "db", ""))
+
+	found, err := reg.AssignmentsInRange("3000-3999")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3005, 3105}, assignmentPorts(found))
+
+	found, err = reg.AssignmentsInRange("3100-3110,31xx,4000")
+	require.NoError(t, err)
+	assert.Equal(t, []int{3105, 4000}, assignmentPorts(found))
+
+	found, err = reg.AssignmentsInRange("5000")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	_, err = reg.AssignmentsInRange("5000-4000")
+	require.ErrorIs(t, err, ErrInvalidPortRange)
+}
+
+func TestNewFromData(t *testing.T) {
+	reg, err := NewFromData("https://example.com/registry.json", []byte(`{"startPort":4000,"assignments":[{"port":4000,"description":"web"}],"blockedPorts":[{"ports":"4001"}]}`))
+	require.NoError(t, err)
+
+	a, ok := reg.GetAssignment(4000)
+	require.True(t, ok)
+	assert.Equal(t, "web", a.Description)
+
+	port, err := reg.NextAvailable()
+	require.NoError(t, err)
+	assert.Equal(t, 4002, port)
+
+	require.ErrorIs(t, reg.AssignPort(4005, "api", ""), ErrReadOnly)
+
+	_, err = NewFromData("https://example.com/registry.json", []byte(`{"assignments":[`))
+	require.ErrorIs(t, err, ErrCorruptRegistry)
+
+	_, err = NewFromData("https://example.com/registry.json", []byte(`{"includes":["team.json"],"assignments":[],"blockedPorts":[]}`))
+	require.Error(t, err)
+}
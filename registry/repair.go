@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ErrNothingToRepair is returned by Repair when the registry file is valid
+var ErrNothingToRepair = errors.New("registry file is valid; nothing to repair")
+
+// RepairResult describes a registry file restored by Repair
+type RepairResult struct {
+	// Source is the file the registry was restored from
+	Source string
+
+	// CorruptPath is where the corrupt registry file was moved, or empty if
+	// there was no registry file
+	CorruptPath string
+}
+
+// backupPath returns the path of the backup Save keeps of the previous
+// contents of the registry file
+func (r *Registry) backupPath() string {
+	return r.path + ".bak"
+}
+
+// writeBackup copies the current registry file, if any, to its backup path
+func (r *Registry) writeBackup() error {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read registry file for backup: %w", err)
+	}
+
+	if err := writeFileMode(r.backupPath(), data, r.fileMode, r.fileModeSet); err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	return nil
+}
+
+// Repair restores a corrupt (empty or unparsable) registry file at path from
+// the temporary file of an interrupted save or the backup written by Save,
+// whichever is the newest that is valid. The corrupt file is kept with a
+// .corrupt suffix. It returns ErrNothingToRepair if the registry file is
+// valid, and the load error if it is invalid in another way, such as a schema
+// error introduced by hand-editing, that a backup should not paper over.
+func Repair(path string) (RepairResult, error) {
+	r := &Registry{path: path, fileMode: DefaultFileMode}
+
+	lock, err := acquireLock(r.lockPath())
+	if err != nil {
+		return RepairResult{}, err
+	}
+	defer func() {
+		unlockFile(lock)
+		lock.Close()
+	}()
+
+	// A missing registry file is restored too
+	data, err := os.ReadFile(path)
+	exists := !errors.Is(err, fs.ErrNotExist)
+	if exists {
+		if err != nil {
+			return RepairResult{}, fmt.Errorf("failed to read registry file: %w", err)
+		}
+		_, err := r.decode(data)
+		if err == nil {
+			return RepairResult{}, ErrNothingToRepair
+		}
+		if !errors.Is(err, ErrCorruptRegistry) {
+			return RepairResult{}, err
+		}
+	}
+
+	var source string
+	var sourceData []byte
+	var sourceInfo os.FileInfo
+	for _, candidate := range []string{path + ".tmp", r.backupPath()} {
+		info, err := os.Stat(candidate)
+		if err != nil {
+			continue
+		}
+		candidateData, err := os.ReadFile(candidate)
+		if err != nil {
+			continue
+		}
+		if _, err := r.decode(candidateData); err != nil {
+			continue
+		}
+		if sourceInfo == nil || info.ModTime().After(sourceInfo.ModTime()) {
+			source, sourceData, sourceInfo = candidate, candidateData, info
+		}
+	}
+	if source == "" {
+		if !exists {
+			return RepairResult{}, fmt.Errorf("registry file %s does not exist and no backup was found", path)
+		}
+		return RepairResult{}, fmt.Errorf("%w: no valid backup found to repair %s from", ErrCorruptRegistry, path)
+	}
+
+	result := RepairResult{Source: source}
+	if exists {
+		result.CorruptPath = path + ".corrupt"
+		if err := os.Rename(path, result.CorruptPath); err != nil {
+			return RepairResult{}, fmt.Errorf("failed to move corrupt registry file: %w", err)
+		}
+	}
+
+	tmpFile := path + ".tmp"
+	if err := writeFileMode(tmpFile, sourceData, sourceInfo.Mode().Perm(), true); err != nil {
+		os.Remove(tmpFile)
+		return RepairResult{}, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		os.Remove(tmpFile)
+		return RepairResult{}, fmt.Errorf("failed to restore registry: %w", err)
+	}
+
+	return result, nil
+}
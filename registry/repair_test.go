@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveWritesBackup(t *testing.T) {
+	reg := createTestRegistry(t)
+	require.NoError(t, reg.AssignPort(8000, "project1", ""))
+	assert.NoFileExists(t, reg.backupPath())
+
+	before, err := os.ReadFile(reg.path)
+	require.NoError(t, err)
+
+	require.NoError(t, reg.AssignPort(8001, "project2", ""))
+	backup, err := os.ReadFile(reg.backupPath())
+	require.NoError(t, err)
+	assert.Equal(t, before, backup)
+}
+
+func TestRepair(t *testing.T) {
+	t.Run("restores a corrupt file from the backup", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+		require.NoError(t, reg.AssignPort(8001, "project2", ""))
+
+		// Truncate the file as a crash might
+		require.NoError(t, os.WriteFile(reg.path, nil, 0644))
+		_, err := New(reg.path)
+		require.ErrorIs(t, err, ErrCorruptRegistry)
+
+		result, err := Repair(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, RepairResult{Source: reg.path + ".bak", CorruptPath: reg.path + ".corrupt"}, result)
+		assert.FileExists(t, reg.path+".corrupt")
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, []int{8000}, assignedPorts(reg2))
+	})
+
+	t.Run("prefers a newer valid temporary file", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+		require.NoError(t, reg.AssignPort(8001, "project2", ""))
+
+		// A save that was interrupted after writing the temporary file
+		data, err := os.ReadFile(reg.path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(reg.path+".tmp", data, 0644))
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(reg.path+".tmp", future, future))
+		require.NoError(t, os.WriteFile(reg.path, []byte(`{"assignments": [`), 0644))
+
+		result, err := Repair(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, reg.path+".tmp", result.Source)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, []int{8000, 8001}, assignedPorts(reg2))
+	})
+
+	t.Run("does nothing to a valid file", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+
+		_, err := Repair(reg.path)
+		assert.ErrorIs(t, err, ErrNothingToRepair)
+	})
+
+	t.Run("fails without a valid backup", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+		require.NoError(t, os.WriteFile(path+".bak", []byte("{"), 0644))
+
+		_, err := Repair(path)
+		assert.ErrorIs(t, err, ErrCorruptRegistry)
+		assert.FileExists(t, path)
+	})
+}
+
+// assignedPorts returns the ports assigned in reg in storage order
+func assignedPorts(reg *Registry) []int {
+	ports := []int{}
+	for _, a := range reg.ListAssignments() {
+		ports = append(ports, a.Port)
+	}
+	return ports
+}
@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseServices reads a services(5) file such as /etc/services and returns a
+// blocked entry for each TCP port, described by its service name. Ports listed
+// more than once keep the first name.
+func ParseServices(r io.Reader) ([]BlockedPort, error) {
+	blocked := []BlockedPort{}
+	seen := make(map[int]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		portStr, proto, ok := strings.Cut(fields[1], "/")
+		if !ok || proto != "tcp" {
+			continue
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || validatePort(port) != nil || seen[port] {
+			continue
+		}
+		seen[port] = true
+
+		blocked = append(blocked, BlockedPort{Ports: portStr, Description: fields[0]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read services: %w", err)
+	}
+
+	return blocked, nil
+}
+
+// BlockListResult describes the outcome of MergeBlockedPorts and BlockFromList
+type BlockListResult struct {
+	// Added are the entries that were blocked
+	Added []BlockedPort
+
+	// AlreadyBlocked are the entries skipped because all their ports were
+	// already blocked
+	AlreadyBlocked []BlockedPort
+
+	// Assigned are the existing assignments that caused an entry to be skipped
+	Assigned []Assignment
+
+	// Removed are the previously blocked entries dropped by BlockFromList in
+	// ImportReplace mode
+	Removed []BlockedPort
+}
+
+// MergeBlockedPorts blocks each entry of blocked that is not already blocked
+// and does not contain an assigned port, then saves once. Invalid specs are an
+// error and nothing is blocked.
+func (r *Registry) MergeBlockedPorts(blocked []BlockedPort) (BlockListResult, error) {
+	var result BlockListResult
+
+	// Clip so appending never writes into prev's backing array
+	prev := r.blockedPorts
+	r.blockedPorts = slices.Clip(r.blockedPorts)
+	added := []BlockedPort{}
+	av := r.newAvailability()
+	for _, bp := range blocked {
+		ranges, err := validateBlockedSpec(bp.Ports)
+		if err != nil {
+			r.blockedPorts = prev
+			return BlockListResult{}, err
+		}
+
+		if av.rangesBlocked(ranges) {
+			result.AlreadyBlocked = append(result.AlreadyBlocked, bp)
+			continue
+		}
+
+		if conflicts := r.assignmentsInRanges(ranges); len(conflicts) > 0 {
+			result.Assigned = append(result.Assigned, conflicts...)
+			continue
+		}
+
+		added = append(added, bp)
+		r.blockedPorts = append(r.blockedPorts, bp)
+		av.blocked = mergeRanges(append(av.blocked, ranges...))
+	}
+
+	if len(added) == 0 {
+		return result, nil
+	}
+
+	if err := r.Save(); err != nil {
+		r.blockedPorts = prev
+		return BlockListResult{}, err
+	}
+
+	result.Added = added
+	return result, nil
+}
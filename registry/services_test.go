@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServices(t *testing.T) {
+	services := `# Network services, Internet style
+tcpmux		1/tcp				# TCP port service multiplexer
+echo		7/tcp
+echo		7/udp
+http		80/tcp		www		# WorldWideWeb HTTP
+www-alt		80/tcp
+bootps		67/udp
+broken		abc/tcp
+
+postgresql	5432/tcp	postgres	# PostgreSQL Database
+`
+
+	blocked, err := ParseServices(strings.NewReader(services))
+	require.NoError(t, err)
+	assert.Equal(t, []BlockedPort{
+		{Ports: "1", Description: "tcpmux"},
+		{Ports: "7", Description: "echo"},
+		{Ports: "80", Description: "http"},
+		{Ports: "5432", Description: "postgresql"},
+	}, blocked)
+}
+
+func TestMergeBlockedPorts(t *testing.T) {
+	t.Run("skips blocked and assigned ports", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.BlockPorts("5000-5100", "existing"))
+		require.NoError(t, reg.AssignPort(8080, "project1", ""))
+
+		result, err := reg.MergeBlockedPorts([]BlockedPort{
+			{Ports: "5050", Description: "inside existing"},
+			{Ports: "8080", Description: "http-alt"},
+			{Ports: "5432", Description: "postgresql"},
+			{Ports: "4999-5001", Description: "partly blocked"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []BlockedPort{
+			{Ports: "5432", Description: "postgresql"},
+			{Ports: "4999-5001", Description: "partly blocked"},
+		}, result.Added)
+		assert.Equal(t, []BlockedPort{{Ports: "5050", Description: "inside existing"}}, result.AlreadyBlocked)
+		require.Len(t, result.Assigned, 1)
+		assert.Equal(t, 8080, result.Assigned[0].Port)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Len(t, reg2.ListBlockedPorts(), 3)
+	})
+
+	t.Run("skips entries blocked earlier in the same merge", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		result, err := reg.MergeBlockedPorts([]BlockedPort{{Ports: "1-65535"}, {Ports: "3*"}, {Ports: "8080"}})
+		require.NoError(t, err)
+		assert.Equal(t, []BlockedPort{{Ports: "1-65535"}}, result.Added)
+		assert.Equal(t, []BlockedPort{{Ports: "3*"}, {Ports: "8080"}}, result.AlreadyBlocked)
+	})
+
+	t.Run("rejects invalid specs", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		_, err := reg.MergeBlockedPorts([]BlockedPort{{Ports: "5432"}, {Ports: "abc"}})
+		assert.ErrorIs(t, err, ErrInvalidPortRange)
+		assert.Empty(t, reg.ListBlockedPorts())
+	})
+}
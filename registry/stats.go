@@ -0,0 +1,104 @@
+package registry
+
+// Stats summarizes the contents of a registry
+type Stats struct {
+	// AssignmentCount is the number of assigned (including reserved) ports
+	AssignmentCount int `json:"assignmentCount"`
+
+	// BlockedRangeCount is the number of blocked entries
+	BlockedRangeCount int `json:"blockedRangeCount"`
+
+	// LowestPort and HighestPort are the lowest and highest assigned ports, or
+	// zero if there are no assignments
+	LowestPort  int `json:"lowestPort"`
+	HighestPort int `json:"highestPort"`
+
+	// AvailableCount is the number of ports in the auto-assignment window that
+	// are neither assigned nor blocked
+	AvailableCount int `json:"availableCount"`
+
+	// LargestFreeBlockStart and LargestFreeBlockLength describe the longest run
+	// of available ports in the auto-assignment window, so the largest block
+	// AssignNextBlock can succeed with. Both are zero if no port is available.
+	LargestFreeBlockStart  int `json:"largestFreeBlockStart"`
+	LargestFreeBlockLength int `json:"largestFreeBlockLength"`
+}
+
+// Stats returns a summary of the registry
+func (r *Registry) Stats() Stats {
+	s := Stats{
+		AssignmentCount:   len(r.assignments),
+		BlockedRangeCount: len(r.blockedPorts),
+		AvailableCount:    len(r.AvailableInRange(r.StartPort(), r.EndPort())),
+	}
+	s.LargestFreeBlockStart, s.LargestFreeBlockLength = r.LargestFreeBlock(r.StartPort(), r.EndPort())
+
+	for i, a := range r.assignments {
+		if i == 0 || a.Port < s.LowestPort {
+			s.LowestPort = a.Port
+		}
+		if i == 0 || a.Port > s.HighestPort {
+			s.HighestPort = a.Port
+		}
+	}
+
+	return s
+}
+
+// LargestFreeBlock returns the first port and length of the longest run of
+// consecutive ports from start to end inclusive that are neither assigned nor
+// blocked. The lowest run wins a tie. Both are zero if no port is available.
+func (r *Registry) LargestFreeBlock(start, end int) (startPort, length int) {
+	start = max(start, minPort)
+	end = min(end, maxPort)
+
+	av := r.newAvailability()
+	runStart, runLength := 0, 0
+	for port := start; port <= end; port++ {
+		if !av.isAvailable(port) {
+			runLength = 0
+			continue
+		}
+
+		if runLength == 0 {
+			runStart = port
+		}
+		runLength++
+
+		if runLength > length {
+			startPort, length = runStart, runLength
+		}
+	}
+
+	return startPort, length
+}
+
+// PortRange is an inclusive range of ports
+type PortRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// FreeRanges returns the runs of available ports from start to end inclusive,
+// in order. Assigned and blocked ports split the runs. Ports outside the valid
+// port range are ignored.
+func (r *Registry) FreeRanges(start, end int) []PortRange {
+	start = max(start, minPort)
+	end = min(end, maxPort)
+
+	av := r.newAvailability()
+	ranges := []PortRange{}
+	for port := start; port <= end; port++ {
+		if !av.isAvailable(port) {
+			continue
+		}
+
+		if n := len(ranges); n > 0 && ranges[n-1].End == port-1 {
+			ranges[n-1].End = port
+		} else {
+			ranges = append(ranges, PortRange{Start: port, End: port})
+		}
+	}
+
+	return ranges
+}
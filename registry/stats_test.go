@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("empty registry", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetEndPort(3199)
+
+		assert.Equal(t, Stats{AvailableCount: 100, LargestFreeBlockStart: 3100, LargestFreeBlockLength: 100}, reg.Stats())
+	})
+
+	t.Run("summarizes assignments and blocked ports", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetEndPort(3199)
+		require.NoError(t, reg.AssignPort(3150, "project1", ""))
+		require.NoError(t, reg.AssignPort(8000, "project2", ""))
+		require.NoError(t, reg.Reserve(3100, "later"))
+		require.NoError(t, reg.BlockPorts("3190-3199", "blocked"))
+		require.NoError(t, reg.BlockPorts("5432", "PostgreSQL"))
+
+		assert.Equal(t, Stats{
+			AssignmentCount:        3,
+			BlockedRangeCount:      2,
+			LowestPort:             3100,
+			HighestPort:            8000,
+			AvailableCount:         88,
+			LargestFreeBlockStart:  3101,
+			LargestFreeBlockLength: 49,
+		}, reg.Stats())
+	})
+}
+
+func TestLargestFreeBlock(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{{Port: 3102}, {Port: 3106}}
+	reg.blockedPorts = []BlockedPort{{Ports: "3110-3120"}}
+
+	start, length := reg.LargestFreeBlock(3100, 3120)
+	assert.Equal(t, 3103, start)
+	assert.Equal(t, 3, length)
+
+	// The lowest of equally long runs wins
+	start, length = reg.LargestFreeBlock(3100, 3104)
+	assert.Equal(t, 3100, start)
+	assert.Equal(t, 2, length)
+
+	start, length = reg.LargestFreeBlock(3110, 3120)
+	assert.Equal(t, 0, start)
+	assert.Equal(t, 0, length)
+}
+
+func TestFreeRanges(t *testing.T) {
+	reg := createTestRegistry(t)
+	reg.assignments = []Assignment{{Port: 3100}, {Port: 3102}, {Port: 3106}}
+	reg.blockedPorts = []BlockedPort{{Ports: "3108-3110"}}
+
+	assert.Equal(t, []PortRange{{3101, 3101}, {3103, 3105}, {3107, 3107}, {3111, 3112}}, reg.FreeRanges(3100, 3112))
+	assert.Equal(t, []PortRange{}, reg.FreeRanges(3108, 3110))
+	assert.Equal(t, []PortRange{{65535, 65535}}, reg.FreeRanges(65535, 70000))
+}
@@ -0,0 +1,81 @@
+package registry
+
+// PortState says whether a port can be assigned, or why it cannot
+type PortState int
+
+const (
+	// PortAvailable means the port can be assigned
+	PortAvailable PortState = iota
+
+	// PortAssigned means the port is assigned in the registry or an included file
+	PortAssigned
+
+	// PortBlocked means the port is covered by a blocked entry
+	PortBlocked
+
+	// PortNotAllowed means the registry has allowed ports and the port is
+	// outside all of them
+	PortNotAllowed
+)
+
+// String returns the name of s as used in output, e.g. "assigned"
+func (s PortState) String() string {
+	switch s {
+	case PortAvailable:
+		return "available"
+	case PortAssigned:
+		return "assigned"
+	case PortBlocked:
+		return "blocked"
+	case PortNotAllowed:
+		return "not allowed"
+	default:
+		return "unknown"
+	}
+}
+
+// PortStatus describes whether a port can be assigned and what prevents it
+type PortStatus struct {
+	Port  int
+	State PortState
+
+	// Assignment is the assignment of the port when State is PortAssigned
+	Assignment Assignment
+
+	// Source is the included registry file the assignment was read from, or ""
+	// if it is assigned in this registry
+	Source string
+
+	// BlockedPort is the first blocked entry covering the port when State is
+	// PortBlocked
+	BlockedPort BlockedPort
+}
+
+// Available reports whether the port can be assigned
+func (s PortStatus) Available() bool {
+	return s.State == PortAvailable
+}
+
+// PortStatus returns whether port can be assigned, and if not, the assignment
+// or blocked entry that prevents it. Assignments are checked before blocked
+// entries, and blocked entries before allowed ports.
+func (r *Registry) PortStatus(port int) PortStatus {
+	if a, ok := r.GetAssignment(port); ok {
+		return PortStatus{Port: port, State: PortAssigned, Assignment: a}
+	}
+	for _, ia := range r.included {
+		if ia.Port == port {
+			return PortStatus{Port: port, State: PortAssigned, Assignment: ia.Assignment, Source: ia.Source}
+		}
+	}
+
+	if bp, ok := r.GetBlockedPort(port); ok {
+		return PortStatus{Port: port, State: PortBlocked, BlockedPort: bp}
+	}
+
+	if !r.isPortAllowed(port) {
+		return PortStatus{Port: port, State: PortNotAllowed}
+	}
+
+	return PortStatus{Port: port, State: PortAvailable}
+}
@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortStatus(t *testing.T) {
+	reg := createTestRegistry(t)
+	require.NoError(t, reg.BlockPorts("5000-5010", "reserved for CI"))
+	require.NoError(t, reg.AssignPort(3100, "web", ""))
+
+	status := reg.PortStatus(3100)
+	assert.Equal(t, PortAssigned, status.State)
+	assert.Equal(t, "web", status.Assignment.Description)
+	assert.Empty(t, status.Source)
+	assert.False(t, status.Available())
+
+	status = reg.PortStatus(5005)
+	assert.Equal(t, PortBlocked, status.State)
+	assert.Equal(t, BlockedPort{Ports: "5000-5010", Description: "reserved for CI"}, status.BlockedPort)
+	assert.False(t, reg.IsPortAvailable(5005))
+
+	status = reg.PortStatus(3101)
+	assert.Equal(t, PortStatus{Port: 3101, State: PortAvailable}, status)
+	assert.True(t, reg.IsPortAvailable(3101))
+
+	_, err := reg.AllowPorts("3000-3999", "")
+	require.NoError(t, err)
+	assert.Equal(t, PortNotAllowed, reg.PortStatus(4000).State)
+	assert.Equal(t, "not allowed", PortNotAllowed.String())
+
+	t.Run("included", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "team.json"), []byte(`{"assignments":[{"port":3200,"description":"api"}],"blockedPorts":[]}`), 0644))
+		path := filepath.Join(dir, "registry.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"includes":["team.json"],"assignments":[],"blockedPorts":[]}`), 0644))
+
+		reg, err := New(path)
+		require.NoError(t, err)
+
+		status := reg.PortStatus(3200)
+		assert.Equal(t, PortAssigned, status.State)
+		assert.Equal(t, "api", status.Assignment.Description)
+		assert.Equal(t, filepath.Join(dir, "team.json"), status.Source)
+	})
+}
@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StreamingThreshold is the registry file size in bytes above which read-only
+// single-port queries should use LookupStreaming instead of loading the whole
+// registry with New
+const StreamingThreshold = 1 << 20
+
+// LookupStreaming returns the assignment of port in the JSON registry file at
+// path without loading the whole registry. Assignments are decoded one at a
+// time. Unlike New it does not take the lock, validate the file, or read
+// included files. YAML registries are not supported and return an error
+// wrapping errors.ErrUnsupported. A port assigned for both TCP and UDP returns
+// ErrAmbiguousProtocol; use LookupStreamingProtocol then.
+func LookupStreaming(path string, port int) (Assignment, bool, error) {
+	return LookupStreamingProtocol(path, port, "")
+}
+
+// LookupStreamingProtocol is like LookupStreaming but only matches the
+// assignment of port for protocol. With a protocol the scan stops at the first
+// match; without one every assignment is scanned so that a port assigned for
+// both protocols is reported.
+func LookupStreamingProtocol(path string, port int, protocol string) (Assignment, bool, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return Assignment{}, false, fmt.Errorf("streaming lookup of YAML registry: %w", errors.ErrUnsupported)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Assignment{}, false, fmt.Errorf("failed to read registry file: %w", err)
+	}
+	defer f.Close()
+
+	corrupt := func(err error) error {
+		return fmt.Errorf("%w: failed to unmarshal registry: %w", ErrCorruptRegistry, err)
+	}
+
+	dec := json.NewDecoder(f)
+	if tok, err := dec.Token(); err != nil {
+		return Assignment{}, false, corrupt(err)
+	} else if tok != json.Delim('{') {
+		return Assignment{}, false, corrupt(fmt.Errorf("expected object, got %v", tok))
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Assignment{}, false, corrupt(err)
+		}
+
+		switch tok {
+		case "version":
+			var version int
+			if err := dec.Decode(&version); err != nil {
+				return Assignment{}, false, corrupt(err)
+			}
+			if version > CurrentVersion {
+				return Assignment{}, false, fmt.Errorf("%w: %d (this portreg supports up to version %d; upgrade portreg to use this registry)", ErrUnsupportedVersion, version, CurrentVersion)
+			}
+		case "assignments":
+			tok, err := dec.Token()
+			if err != nil {
+				return Assignment{}, false, corrupt(err)
+			}
+			if tok == nil {
+				continue
+			}
+			if tok != json.Delim('[') {
+				return Assignment{}, false, corrupt(fmt.Errorf("expected assignments array, got %v", tok))
+			}
+			var found *Assignment
+			for dec.More() {
+				var a Assignment
+				if err := dec.Decode(&a); err != nil {
+					return Assignment{}, false, corrupt(err)
+				}
+				if a.Port != port || (protocol != "" && a.EffectiveProtocol() != protocol) {
+					continue
+				}
+				if protocol != "" {
+					return a, true, nil
+				}
+				if found != nil {
+					return Assignment{}, false, fmt.Errorf("%w: port %d is assigned for both %s and %s", ErrAmbiguousProtocol, port, ProtocolTCP, ProtocolUDP)
+				}
+				found = &a
+			}
+			if found != nil {
+				return *found, true, nil
+			}
+			if _, err := dec.Token(); err != nil {
+				return Assignment{}, false, corrupt(err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return Assignment{}, false, corrupt(err)
+			}
+		}
+	}
+
+	return Assignment{}, false, nil
+}
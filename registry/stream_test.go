@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupStreaming(t *testing.T) {
+	reg := createTestRegistry(t)
+	require.NoError(t, reg.BlockPorts("5432", "postgres"))
+	require.NoError(t, reg.Assign(Assignment{Port: 3100, Description: "web", Path: "/tmp/web", Tags: []string{"env:dev"}}))
+	require.NoError(t, reg.AssignPort(3101, "api", ""))
+
+	a, ok, err := LookupStreaming(reg.path, 3101)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Assignment{Port: 3101, Description: "api", CreatedAt: testNow}, a)
+
+	a, ok, err = LookupStreaming(reg.path, 3100)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"env:dev"}, a.Tags)
+
+	_, ok, err = LookupStreaming(reg.path, 5432)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	t.Run("protocol", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Assign(Assignment{Port: 5000, Description: "web"}))
+		require.NoError(t, reg.Assign(Assignment{Port: 5000, Description: "stats", Protocol: ProtocolUDP}))
+
+		_, _, err := LookupStreaming(reg.path, 5000)
+		require.ErrorIs(t, err, ErrAmbiguousProtocol)
+
+		a, ok, err := LookupStreamingProtocol(reg.path, 5000, ProtocolUDP)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "stats", a.Description)
+
+		a, ok, err = LookupStreamingProtocol(reg.path, 5000, ProtocolTCP)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "web", a.Description)
+	})
+
+	t.Run("keys in any order", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"blockedPorts":[{"ports":"1-2"}],"startPort":4000,"assignments":[{"port":4000,"description":"a"}]}`), 0644))
+
+		a, ok, err := LookupStreaming(path, 4000)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "a", a.Description)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"assignments":[{"port":4000},`), 0644))
+
+		_, _, err := LookupStreaming(path, 5000)
+		require.ErrorIs(t, err, ErrCorruptRegistry)
+	})
+
+	t.Run("newer version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"version":99,"assignments":[]}`), 0644))
+
+		_, _, err := LookupStreaming(path, 4000)
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		_, _, err := LookupStreaming(filepath.Join(t.TempDir(), "registry.yaml"), 4000)
+		require.ErrorIs(t, err, errors.ErrUnsupported)
+	})
+}
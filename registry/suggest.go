@@ -0,0 +1,29 @@
+package registry
+
+// suggestSteps are the roundness levels Suggest tries, roundest first
+var suggestSteps = []int{100, 10}
+
+// Suggest returns an available port between the start and end ports that is
+// easy to remember. It prefers the lowest available multiple of 100, then of
+// 10, skipping ports commonly used by development tools (see NoteworthyPort).
+// If there is no such port it returns the next available port like
+// NextAvailable. Nothing is assigned.
+func (r *Registry) Suggest() (int, error) {
+	startPort, endPort := r.StartPort(), r.EndPort()
+	av := r.newAvailability()
+
+	for _, step := range suggestSteps {
+		first := (startPort + step - 1) / step * step
+		for port := first; port <= endPort; port += step {
+			if _, ok := NoteworthyPort(port); ok {
+				continue
+			}
+			if r.canAutoAssign(av, port, ProtocolTCP) {
+				r.log().Debug("suggested port", "port", port, "step", step)
+				return port, nil
+			}
+		}
+	}
+
+	return r.NextAvailable()
+}
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggest(t *testing.T) {
+	reg := createTestRegistry(t)
+
+	port, err := reg.Suggest()
+	require.NoError(t, err)
+	assert.Equal(t, 3100, port)
+	assert.Empty(t, reg.assignments, "Suggest must not assign")
+
+	require.NoError(t, reg.AssignPort(3100, "web", ""))
+	require.NoError(t, reg.BlockPorts("3200-3299", ""))
+	port, err = reg.Suggest()
+	require.NoError(t, err)
+	assert.Equal(t, 3300, port)
+
+	t.Run("skips noteworthy ports", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetStartPort(2995)
+		reg.SetEndPort(3050)
+
+		port, err := reg.Suggest()
+		require.NoError(t, err)
+		assert.Equal(t, 3010, port)
+	})
+
+	t.Run("falls back to multiples of 10", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetStartPort(3101)
+		reg.SetEndPort(3150)
+
+		port, err := reg.Suggest()
+		require.NoError(t, err)
+		assert.Equal(t, 3110, port)
+	})
+
+	t.Run("falls back to next available", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetStartPort(3101)
+		reg.SetEndPort(3109)
+		require.NoError(t, reg.AssignPort(3101, "web", ""))
+
+		port, err := reg.Suggest()
+		require.NoError(t, err)
+		assert.Equal(t, 3102, port)
+	})
+
+	t.Run("no ports available", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetStartPort(3101)
+		reg.SetEndPort(3101)
+		require.NoError(t, reg.AssignPort(3101, "web", ""))
+
+		_, err := reg.Suggest()
+		assert.ErrorIs(t, err, ErrNoPortsAvailable)
+	})
+}
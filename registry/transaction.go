@@ -0,0 +1,49 @@
+package registry
+
+import "slices"
+
+// Transaction runs fn against an in-memory copy of the registry. Mutations made
+// through tx are not saved as they happen. If fn returns nil, the changes are
+// applied to r and saved once; otherwise they are discarded and the error from
+// fn is returned. If the final save fails r is left unchanged.
+func (r *Registry) Transaction(fn func(tx *Registry) error) error {
+	tx := r.clone()
+	// Mutating methods save after every change; keep those changes in memory
+	tx.dryRun = true
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	prevAssignments, prevBlockedPorts, prevAllowedPorts := r.assignments, r.blockedPorts, r.allowedPorts
+	r.assignments, r.blockedPorts, r.allowedPorts = tx.assignments, tx.blockedPorts, tx.allowedPorts
+
+	if err := r.Save(); err != nil {
+		r.assignments, r.blockedPorts, r.allowedPorts = prevAssignments, prevBlockedPorts, prevAllowedPorts
+		return err
+	}
+	r.recordAudit(tx.pendingAudit...)
+
+	return nil
+}
+
+// clone returns a copy of r that shares no assignment or blocked port data
+// with r. The clone does not hold r's lock.
+func (r *Registry) clone() *Registry {
+	c := *r
+	c.lockFile = nil
+	c.pendingAudit = nil
+
+	c.assignments = make([]Assignment, len(r.assignments))
+	for i, a := range r.assignments {
+		a.Tags = slices.Clone(a.Tags)
+		c.assignments[i] = a
+	}
+	c.blockedPorts = slices.Clone(r.blockedPorts)
+	if c.blockedPorts == nil {
+		c.blockedPorts = []BlockedPort{}
+	}
+	c.allowedPorts = slices.Clone(r.allowedPorts)
+
+	return &c
+}
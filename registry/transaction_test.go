@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction(t *testing.T) {
+	t.Run("commits all changes with one save", func(t *testing.T) {
+		reg := createTestRegistry(t)
+
+		err := reg.Transaction(func(tx *Registry) error {
+			if err := tx.AssignPort(8000, "project1", ""); err != nil {
+				return err
+			}
+			if _, err := tx.AssignNextAvailable("project2", ""); err != nil {
+				return err
+			}
+			// Nothing is written until the transaction commits
+			if _, err := os.Stat(reg.path); !os.IsNotExist(err) {
+				return errors.New("registry file was written during transaction")
+			}
+			return tx.BlockPorts("9000-9010", "blocked")
+		})
+		require.NoError(t, err)
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Len(t, reg2.ListAssignments(), 2)
+		assert.Len(t, reg2.ListBlockedPorts(), 1)
+	})
+
+	t.Run("discards all changes on error", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.AssignPort(8000, "project1", ""))
+
+		err := reg.Transaction(func(tx *Registry) error {
+			if err := tx.AssignPort(8001, "project2", ""); err != nil {
+				return err
+			}
+			if err := tx.UnassignPort(8000); err != nil {
+				return err
+			}
+			return tx.AssignPort(8001, "conflict", "")
+		})
+		assert.ErrorIs(t, err, ErrPortAlreadyAssigned)
+
+		assert.Equal(t, []Assignment{{Port: 8000, Description: "project1", CreatedAt: testNow}}, reg.ListAssignments())
+
+		reg2, err := New(reg.path)
+		require.NoError(t, err)
+		assert.Equal(t, reg.ListAssignments(), reg2.ListAssignments())
+	})
+
+	t.Run("deep copies assignments", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		require.NoError(t, reg.Assign(Assignment{Port: 8000, Tags: []string{"env:dev"}}))
+
+		err := reg.Transaction(func(tx *Registry) error {
+			tx.assignments[0].Tags[0] = "env:prod"
+			return errors.New("abort")
+		})
+		assert.Error(t, err)
+
+		a, ok := reg.GetAssignment(8000)
+		require.True(t, ok)
+		assert.Equal(t, []string{"env:dev"}, a.Tags)
+	})
+
+	t.Run("leaves registry unchanged if save fails", func(t *testing.T) {
+		reg := createTestRegistry(t)
+		reg.SetReadOnly(true)
+
+		err := reg.Transaction(func(tx *Registry) error {
+			return tx.AssignPort(8000, "project1", "")
+		})
+		assert.ErrorIs(t, err, ErrReadOnly)
+		assert.Empty(t, reg.ListAssignments())
+	})
+}